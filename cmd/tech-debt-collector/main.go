@@ -7,13 +7,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"runtime"
+	"strings"
 	"time"
 
+	"tech-debt-collector/internal/config"
 	"tech-debt-collector/internal/detector"
+	"tech-debt-collector/internal/goast"
 	"tech-debt-collector/internal/llm"
 	"tech-debt-collector/internal/models"
+	"tech-debt-collector/internal/pipeline"
 	"tech-debt-collector/internal/scanner"
 	"tech-debt-collector/internal/scorer"
+	"tech-debt-collector/internal/vcs"
 
 	"github.com/joho/godotenv"
 )
@@ -22,13 +28,22 @@ func main() {
 	_ = godotenv.Load()
 
 	// Define flags
-	repoPath := flag.String("path", ".", "Repository path to scan")
+	repoPath := flag.String("path", ".", "Repository path or .zip/.tar.gz archive to scan")
 	outputPath := flag.String("output", "report.json", "Output file path")
 	outputFormat := flag.String("format", "json", "Output format: json or text")
 	enableLLM := flag.Bool("llm", true, "Enable LLM enrichment")
 	verbose := flag.Bool("verbose", false, "Verbose output")
 	openAIKey := flag.String("openai-key", os.Getenv("OPENAI_API_KEY"), "OpenAI API key")
 	openAIModel := flag.String("openai-model", "gpt-3.5-turbo", "OpenAI model")
+	noIgnore := flag.Bool("no-ignore", false, "Don't respect .gitignore/.ignore files")
+	since := flag.String("since", "", "Only scan files changed since this git ref (e.g. origin/main)")
+	staged := flag.Bool("staged", false, "Only scan files with staged changes")
+	changedLines := flag.Bool("changed-lines", true, "With -since/-staged, report only debt on added or modified lines")
+	ref := flag.String("ref", "", "Scan the tree at this git commit or tag instead of the working directory")
+	includeGenerated := flag.Bool("include-generated", false, "Also scan generated, minified and binary files")
+	workers := flag.Int("workers", runtime.GOMAXPROCS(0), "Number of concurrent detection workers")
+	configPath := flag.String("config", "", "Marker config file (default <path>/.techdebt)")
+	noSuppress := flag.Bool("no-suppress", false, "Disregard techdebt:ignore/snooze directives and score every item")
 	help := flag.Bool("help", false, "Show help")
 
 	flag.Parse()
@@ -38,16 +53,41 @@ func main() {
 		os.Exit(0)
 	}
 
-	err := runAnalysis(*repoPath, *outputPath, *outputFormat, *enableLLM, *verbose, *openAIKey, *openAIModel)
+	cfg := &models.Config{
+		OpenAIAPIKey: *openAIKey,
+		OpenAIModel:  *openAIModel,
+		ScannerConfig: models.ScannerConfig{
+			RootPath:         *repoPath,
+			ExcludeDirs:      []string{".git", "node_modules", "vendor", "build", ".venv", ".next"},
+			SkipHiddenDirs:   true,
+			UseIgnoreFiles:   !*noIgnore,
+			Since:            *since,
+			Staged:           *staged,
+			ChangedLinesOnly: *changedLines,
+			Ref:              *ref,
+			IncludeGenerated: *includeGenerated,
+		},
+		EnableLLM:    *enableLLM,
+		OutputFormat: *outputFormat,
+		OutputPath:   *outputPath,
+		Verbose:      *verbose,
+		Workers:      *workers,
+		ConfigPath:   *configPath,
+		NoSuppress:   *noSuppress,
+	}
+
+	err := runAnalysis(cfg)
 
 	if err != nil {
 		log.Fatalf("❌ Error: %v", err)
 	}
 }
 
-func runAnalysis(repoPath, outputPath, outputFormat string, enableLLM, verbose bool, openAIKey, openAIModel string) error {
+func runAnalysis(cfg *models.Config) error {
 	log.Println("🔍 Tech Debt Collector Analysis Starting...")
 
+	repoPath := cfg.ScannerConfig.RootPath
+
 	// Validate inputs
 	if _, err := os.Stat(repoPath); os.IsNotExist(err) {
 		return fmt.Errorf("repository path does not exist: %s", repoPath)
@@ -57,37 +97,87 @@ func runAnalysis(repoPath, outputPath, outputFormat string, enableLLM, verbose b
 	log.Printf("📁 Scanning repository: %s\n", repoPath)
 	s := scanner.NewScanner(
 		repoPath,
-		[]string{".git", "node_modules", "vendor", "build", ".venv", ".next"},
-		[]string{},
-		true,
+		cfg.ScannerConfig.ExcludeDirs,
+		cfg.ScannerConfig.IncludeExtensions,
+		cfg.ScannerConfig.SkipHiddenDirs,
 	)
+	s.UseIgnoreFiles = cfg.ScannerConfig.UseIgnoreFiles
+	s.IncludeGenerated = cfg.ScannerConfig.IncludeGenerated
+
+	ctx := context.Background()
+
+	// Read files from a historical revision instead of the working directory
+	if cfg.ScannerConfig.Ref != "" {
+		if cfg.ScannerConfig.Since != "" || cfg.ScannerConfig.Staged {
+			return fmt.Errorf("-ref cannot be combined with -since or -staged")
+		}
+		tree, err := vcs.OpenTree(ctx, repoPath, cfg.ScannerConfig.Ref)
+		if err != nil {
+			return fmt.Errorf("git tree error: %w", err)
+		}
+		defer tree.Close()
+		s.FS = tree
+		repoPath = fmt.Sprintf("%s@%s", repoPath, cfg.ScannerConfig.Ref)
+		log.Printf("   Reading tree at %s\n", cfg.ScannerConfig.Ref)
+	}
 
-	files, err := s.ScanFiles()
+	// Restrict to changed files when diffing against git
+	changes, err := loadChanges(ctx, &cfg.ScannerConfig)
 	if err != nil {
-		return fmt.Errorf("scan error: %w", err)
+		return fmt.Errorf("git diff error: %w", err)
+	}
+	if changes != nil {
+		s.OnlyFiles = changes.FileSet()
+		log.Printf("   %d files changed\n", len(s.OnlyFiles))
 	}
-	log.Printf("   Found %d source files\n", len(files))
 
 	// Step 2: Detect technical debt
 	log.Println("🔎 Detecting technical debt items...")
 
-	det := detector.NewDetector()
-	var allItems []models.DebtItem
-
-	for _, filePath := range files {
-		items, err := det.DetectInFile(filePath, s.GetFileImportance(filePath))
-		if err != nil {
-			if verbose {
-				log.Printf("   Warning: Could not scan %s: %v\n", filePath, err)
-			}
-			continue
+	markers, err := loadConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("config error: %w", err)
+	}
+	det, err := detector.NewDetectorWithConfig(markers)
+	if err != nil {
+		return fmt.Errorf("config error: %w", err)
+	}
+	if s.FS == nil {
+		det.Root = cfg.ScannerConfig.RootPath // Keep item IDs independent of the checkout path
+	}
+	det.Partial = s.OnlyFiles != nil
+	p := pipeline.New(s, det, cfg.Workers)
+	p.OnError = func(path string, err error) {
+		if cfg.Verbose {
+			log.Printf("   Warning: Could not scan %s: %v\n", path, err)
 		}
-		allItems = append(allItems, items...)
+	}
+
+	allItems, err := p.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("scan error: %w", err)
+	}
+	log.Printf("   Found %d source files\n", p.FilesScanned)
+	if len(s.Skipped) > 0 {
+		log.Printf("   Skipped %d generated, minified or binary files\n", len(s.Skipped))
+	}
+
+	if changes != nil && cfg.ScannerConfig.ChangedLinesOnly {
+		allItems = changes.FilterItems(cfg.ScannerConfig.RootPath, allItems)
 	}
 	log.Printf("   Found %d debt items\n", len(allItems))
 
-	// Step 3: Calculate frequency
-	det.CalculateFrequency(allItems, repoPath)
+	// Directives take items out of scoring, but reviewers can still audit them
+	var suppressed []models.DebtItem
+	if !cfg.NoSuppress {
+		allItems, suppressed = detector.SplitSuppressed(allItems)
+		if len(suppressed) > 0 {
+			log.Printf("   %d items suppressed by techdebt: directives\n", len(suppressed))
+		}
+	}
+
+	// Step 3: Group similar items across the repository
+	clusters := det.ClusterItems(allItems)
 
 	// Step 4: Score items
 	log.Println("📊 Scoring risk...")
@@ -100,10 +190,9 @@ func runAnalysis(repoPath, outputPath, outputFormat string, enableLLM, verbose b
 		critical, high, medium, low)
 
 	// Step 5: Enrich with LLM (optional)
-	if enableLLM && openAIKey != "" {
+	if cfg.EnableLLM && cfg.OpenAIAPIKey != "" {
 		log.Println("🤖 Enriching with LLM analysis...")
-		client := llm.NewOpenAIClient(openAIKey, openAIModel, verbose)
-		ctx := context.Background()
+		client := llm.NewOpenAIClient(cfg.OpenAIAPIKey, cfg.OpenAIModel, cfg.Verbose)
 
 		// Enrich top 10 items
 		limit := 10
@@ -113,10 +202,10 @@ func runAnalysis(repoPath, outputPath, outputFormat string, enableLLM, verbose b
 
 		for i := 0; i < limit; i++ {
 			if err := client.EnrichItem(ctx, &allItems[i]); err != nil {
-				if verbose {
+				if cfg.Verbose {
 					log.Printf("   Warning: Could not enrich item %d: %v\n", i, err)
 				}
-			} else if verbose {
+			} else if cfg.Verbose {
 				log.Printf("   ✓ Enriched: %s\n", allItems[i].Type)
 			}
 			// Rate limit
@@ -126,7 +215,7 @@ func runAnalysis(repoPath, outputPath, outputFormat string, enableLLM, verbose b
 		// Generate report summary
 		report := createReport(allItems, repoPath, critical, high, medium, low)
 		if err := client.EnrichReport(ctx, &report); err != nil {
-			if verbose {
+			if cfg.Verbose {
 				log.Printf("   Warning: Could not enrich report: %v\n", err)
 			}
 		} else {
@@ -135,19 +224,36 @@ func runAnalysis(repoPath, outputPath, outputFormat string, enableLLM, verbose b
 	}
 
 	// Step 6: Output results
-	log.Printf("💾 Writing report to: %s\n", outputPath)
+	log.Printf("💾 Writing report to: %s\n", cfg.OutputPath)
 	report := createReport(allItems, repoPath, critical, high, medium, low)
+	report.SkippedFiles = s.Skipped
+	report.Suppressed = suppressed
+	report.DeprecatedSymbols = goast.CountUsages(allItems)
+	report.Clusters = topClusters(clusters, 10)
+	report.DocCoverage = det.DocCoverage()
 
-	if err := writeReport(&report, outputPath, outputFormat); err != nil {
+	if err := writeReport(&report, cfg.OutputPath, cfg.OutputFormat); err != nil {
 		return fmt.Errorf("write error: %w", err)
 	}
 
 	// Print summary
-	printSummary(&report, outputPath)
+	printSummary(&report, cfg.OutputPath)
 
 	return nil
 }
 
+// loadChanges returns the git change set selected by -since/-staged, or nil
+func loadChanges(ctx context.Context, sc *models.ScannerConfig) (*vcs.ChangeSet, error) {
+	switch {
+	case sc.Staged:
+		return vcs.StagedChanges(ctx, sc.RootPath)
+	case sc.Since != "":
+		return vcs.ChangedSince(ctx, sc.RootPath, sc.Since)
+	default:
+		return nil, nil
+	}
+}
+
 // createReport builds the final report
 func createReport(items []models.DebtItem, repoPath string, critical, high, medium, low int) models.Report {
 	return models.Report{
@@ -200,6 +306,15 @@ func writeText(report *models.Report, filePath string) error {
 	content += fmt.Sprintf("  Critical: %d | High: %d | Medium: %d | Low: %d\n\n",
 		report.CriticalItems, report.HighItems, report.MediumItems, report.LowItems)
 
+	if len(report.SkippedFiles) > 0 {
+		reasons := make(map[string]int)
+		for _, f := range report.SkippedFiles {
+			reasons[f.Reason]++
+		}
+		content += fmt.Sprintf("  Skipped Files: %d (generated: %d, minified: %d, binary: %d)\n\n",
+			len(report.SkippedFiles), reasons["generated"], reasons["minified"], reasons["binary"])
+	}
+
 	if report.Summary != "" && report.Summary != "Technical Debt Analysis Report" {
 		content += fmt.Sprintf("LLM ANALYSIS:\n%s\n\n", report.Summary)
 	}
@@ -211,14 +326,61 @@ func writeText(report *models.Report, filePath string) error {
 		if i >= 10 {
 			break
 		}
-		content += fmt.Sprintf("\n%d. [%s] %s:%d\n", i+1, item.Type, item.FilePath, item.LineNumber)
+		location := fmt.Sprintf("%s:%d", item.FilePath, item.LineNumber)
+		if item.EndLine > item.LineNumber {
+			location += fmt.Sprintf("-%d", item.EndLine)
+		}
+		content += fmt.Sprintf("\n%d. [%s] %s\n", i+1, item.Type, location)
 		content += fmt.Sprintf("   Message: %s\n", item.Message)
+		if tags := itemTags(item); tags != "" {
+			content += fmt.Sprintf("   %s\n", tags)
+		}
 		content += fmt.Sprintf("   Risk: %.1f/100 | Severity: %d/5\n", item.Risk, item.Severity)
 		if item.LLMExplanation != "" {
 			content += fmt.Sprintf("   Analysis: %s\n", item.LLMExplanation)
 		}
 	}
 
+	if len(report.Clusters) > 0 {
+		content += "\n\nTOP CLUSTERS:\n"
+		content += "─────────────────────────────────────────────────────────────\n"
+		for _, c := range report.Clusters {
+			content += fmt.Sprintf("- %d items in %d files [%s] %s\n", c.Size, c.Files, c.Type, c.Message)
+		}
+	}
+
+	if len(report.DeprecatedSymbols) > 0 {
+		content += "\n\nDEPRECATED SYMBOLS IN USE:\n"
+		content += "─────────────────────────────────────────────────────────────\n"
+		for _, sym := range report.DeprecatedSymbols {
+			content += fmt.Sprintf("- %s (%s): %d usages\n", sym.Symbol, sym.DeclaredAt, sym.Usages)
+		}
+	}
+
+	if len(report.DocCoverage) > 0 {
+		content += "\n\nDOC COVERAGE (least documented first):\n"
+		content += "─────────────────────────────────────────────────────────────\n"
+		for _, pkg := range report.DocCoverage {
+			content += fmt.Sprintf("- %s (%s): %.1f%% of %d exported\n", pkg.Name, pkg.Package, pkg.Coverage, pkg.Exported)
+		}
+	}
+
+	if len(report.Suppressed) > 0 {
+		content += fmt.Sprintf("\n\nSUPPRESSED (%d):\n", len(report.Suppressed))
+		content += "─────────────────────────────────────────────────────────────\n"
+		for _, item := range report.Suppressed {
+			sup := item.Suppression
+			content += fmt.Sprintf("- [%s] %s:%d %s (techdebt:%s", item.Type, item.FilePath, item.LineNumber, item.Message, sup.Directive)
+			if sup.Until != nil {
+				content += " until " + sup.Until.Format("2006-01-02")
+			}
+			if sup.Reason != "" {
+				content += ": " + sup.Reason
+			}
+			content += ")\n"
+		}
+	}
+
 	if len(report.Recommendations) > 0 {
 		content += "\n\nRECOMMENDATIONS:\n"
 		content += "─────────────────────────────────────────────────────────────\n"
@@ -232,6 +394,38 @@ func writeText(report *models.Report, filePath string) error {
 	return os.WriteFile(filePath, []byte(content), 0644)
 }
 
+// topClusters returns up to n of the largest clusters with more than one item
+func topClusters(clusters []models.Cluster, n int) []models.Cluster {
+	var top []models.Cluster
+	for _, c := range clusters {
+		if c.Size < 2 || len(top) == n {
+			break
+		}
+		top = append(top, c)
+	}
+	return top
+}
+
+// itemTags describes the owner, issues and deadline parsed from an item
+func itemTags(item models.DebtItem) string {
+	var tags []string
+	if item.Owner != "" {
+		tags = append(tags, "Owner: "+item.Owner)
+	}
+	if len(item.IssueRefs) > 0 {
+		tags = append(tags, "Issues: "+strings.Join(item.IssueRefs, ", "))
+	}
+	switch {
+	case item.DueDate != nil && item.DueDate.Before(time.Now()):
+		tags = append(tags, "Due: "+item.DueDate.Format("2006-01-02")+" (OVERDUE)")
+	case item.DueDate != nil:
+		tags = append(tags, "Due: "+item.DueDate.Format("2006-01-02"))
+	case item.DueVersion != "":
+		tags = append(tags, "Due: "+item.DueVersion)
+	}
+	return strings.Join(tags, " | ")
+}
+
 // printSummary prints summary to stdout
 func printSummary(report *models.Report, outputPath string) {
 	fmt.Println("\n✅ Analysis Complete!")
@@ -243,6 +437,21 @@ func printSummary(report *models.Report, outputPath string) {
 	fmt.Printf("\n   Report saved to: %s\n", outputPath)
 }
 
+// loadConfig reads the -config file, or the .techdebt file at the root of
+// a scanned directory if there is one
+func loadConfig(cfg *models.Config) (*config.Config, error) {
+	if cfg.ConfigPath != "" {
+		if _, err := os.Stat(cfg.ConfigPath); err != nil {
+			return nil, err
+		}
+		return config.LoadFile(cfg.ConfigPath)
+	}
+	if info, err := os.Stat(cfg.ScannerConfig.RootPath); err == nil && info.IsDir() {
+		return config.Load(cfg.ScannerConfig.RootPath)
+	}
+	return &config.Config{}, nil
+}
+
 // printHelp displays help information
 func printHelp() {
 	fmt.Print(`
@@ -252,13 +461,22 @@ USAGE:
   tech-debt-collector [flags]
 
 FLAGS:
-  -path string              Repository path to scan (default ".")
+  -path string              Repository path or .zip/.tar.gz archive to scan (default ".")
   -output string            Output file path (default "report.json")
   -format string            Output format: json or text (default "json")
   -llm                      Enable LLM enrichment (default true)
   -verbose                  Verbose output (default false)
   -openai-key string        OpenAI API key (from OPENAI_API_KEY env var)
   -openai-model string      OpenAI model to use (default "gpt-3.5-turbo")
+  -no-ignore                Don't respect .gitignore/.ignore files (default false)
+  -since string             Only scan files changed since a git ref (e.g. origin/main)
+  -staged                   Only scan files with staged changes (default false)
+  -changed-lines            With -since/-staged, report only debt on changed lines (default true)
+  -ref string               Scan the tree at a git commit or tag without checking it out
+  -include-generated        Also scan generated, minified and binary files (default false)
+  -workers int              Number of concurrent detection workers (default GOMAXPROCS)
+  -config string            Marker config file (default <path>/.techdebt)
+  -no-suppress              Disregard techdebt:ignore/snooze directives (default false)
   -help                     Show this help message
 
 EXAMPLES:
@@ -271,9 +489,28 @@ EXAMPLES:
   # Generate text report
   tech-debt-collector -format text -output report.txt
 
+  # Debt introduced by the current branch
+  tech-debt-collector -since origin/main -format text -output pr.txt
+
+  # Audit a vendor drop without extracting it
+  tech-debt-collector -path vendor-drop.tar.gz
+
+  # Debt as of a release tag
+  tech-debt-collector -ref v1.4 -output debt-v1.4.json
+
+  # Custom markers, e.g. {"markers": [{"name": "OPTIMIZE", "severity": 2}]}
+  tech-debt-collector -config team.techdebt
+
   # Verbose analysis with GPT-4
   tech-debt-collector -llm -verbose -openai-model gpt-4
 
+DIRECTIVES:
+  Comments can acknowledge debt; such items are listed as suppressed, not scored.
+  techdebt:ignore           Items on this line or in this comment
+  techdebt:ignore-next-line Items starting on the next line
+  techdebt:snooze until=2026-11-01 reason="..."
+                            Like ignore until the date, then back with higher severity
+
 ENVIRONMENT:
   OPENAI_API_KEY            Your OpenAI API key (optional)
 
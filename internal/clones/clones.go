@@ -0,0 +1,375 @@
+// Package clones finds duplicated runs of tokens across source files.
+//
+// Files are tokenized, and each run of k tokens is hashed with a rolling
+// hash. Winnowing keeps only the minimum hash of every w consecutive runs,
+// which guarantees that any match of at least MinTokens = k+w-1 tokens is
+// seen while indexing only a fraction of the positions. Matches found from
+// the index are then verified and extended token by token.
+package clones
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// DefaultMinTokens is the shortest duplicated run reported by default
+const DefaultMinTokens = 100
+
+// rollBase is the multiplier of the polynomial rolling hash
+const rollBase = 1000003
+
+// Location is one copy of a clone
+type Location struct {
+	Path               string
+	StartLine, EndLine int
+}
+
+// Group is a run of tokens found at two or more locations
+type Group struct {
+	Tokens    int // Length of the shortest copy
+	Locations []Location
+}
+
+// Index collects tokenized files. Files may be added from several
+// goroutines at once.
+type Index struct {
+	MinTokens int
+
+	mu    sync.Mutex
+	files []*file
+}
+
+// file keeps only what matching needs: a hash per token, and where each
+// line holding tokens starts
+type file struct {
+	path   string
+	tokens []uint32
+	starts []int32 // Index of the first token of each line in lines
+	lines  []int32
+}
+
+// line returns the line number of the token at pos
+func (f *file) line(pos int) int {
+	i := sort.Search(len(f.starts), func(i int) bool { return int(f.starts[i]) > pos })
+	return int(f.lines[i-1])
+}
+
+// region is a verified match in one file, in token positions
+type region struct {
+	file, start, end int // end is exclusive
+}
+
+// NewIndex creates an index reporting runs of at least minTokens tokens;
+// minTokens <= 0 means DefaultMinTokens
+func NewIndex(minTokens int) *Index {
+	if minTokens <= 0 {
+		minTokens = DefaultMinTokens
+	}
+	return &Index{MinTokens: minTokens}
+}
+
+// Add tokenizes a file's code lines, which must not contain comments, and
+// reports whether it is long enough to hold a clone
+func (x *Index) Add(path string, code []string) bool {
+	f := &file{path: path}
+	for i, line := range code {
+		toks := tokenize(line)
+		if len(toks) == 0 {
+			continue
+		}
+		f.starts = append(f.starts, int32(len(f.tokens)))
+		f.lines = append(f.lines, int32(i+1))
+		for _, tok := range toks {
+			h := fnv.New32a()
+			h.Write([]byte(tok))
+			f.tokens = append(f.tokens, h.Sum32())
+		}
+	}
+	if len(f.tokens) < x.MinTokens {
+		return false
+	}
+
+	x.mu.Lock()
+	x.files = append(x.files, f)
+	x.mu.Unlock()
+	return true
+}
+
+// Reset forgets the files added so far
+func (x *Index) Reset() {
+	x.mu.Lock()
+	x.files = nil
+	x.mu.Unlock()
+}
+
+// Groups finds the clones among the files added, ordered by their first
+// location
+func (x *Index) Groups() []Group {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	sort.SliceStable(x.files, func(i, j int) bool { return x.files[i].path < x.files[j].path })
+
+	window := min(16, max(1, x.MinTokens/4))
+	k := x.MinTokens - window + 1
+
+	// Fingerprints of every file, sorted so that equal hashes are adjacent.
+	// A flat slice costs far less than a map of slices on large repositories.
+	type occurrence struct {
+		hash      uint64
+		file, pos int32
+	}
+	var index []occurrence
+	for fi, f := range x.files {
+		for _, fp := range winnow(f.tokens, k, window) {
+			index = append(index, occurrence{fp.hash, int32(fi), int32(fp.pos)})
+		}
+	}
+	sort.Slice(index, func(i, j int) bool {
+		a, b := index[i], index[j]
+		if a.hash != b.hash {
+			return a.hash < b.hash
+		}
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		return a.pos < b.pos
+	})
+
+	regions := make(map[region]int)
+	var parent []int
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	add := func(r region) int {
+		if i, ok := regions[r]; ok {
+			return i
+		}
+		regions[r] = len(parent)
+		parent = append(parent, len(parent))
+		return len(parent) - 1
+	}
+
+	// Regions already extended from a seed, by file pair and diagonal. Any
+	// other seed inside one extends to the same match, so it is skipped:
+	// without this, copies of a large file are re-verified from every seed.
+	type diagonal struct{ fa, fb, offset int }
+	verified := make(map[diagonal][]region)
+
+	for i := 0; i < len(index); {
+		j := i + 1
+		for j < len(index) && index[j].hash == index[i].hash {
+			j++
+		}
+		first := index[i]
+		for _, o := range index[i+1 : j] {
+			pa, pb := int(first.pos), int(o.pos)
+			d := diagonal{int(first.file), int(o.file), pb - pa}
+			if covered(verified[d], pa) {
+				continue
+			}
+			a, b, ok := x.extend(d.fa, pa, d.fb, pb)
+			verified[d] = append(verified[d], a)
+			if !ok {
+				continue
+			}
+			ra, rb := find(add(a)), find(add(b))
+			if ra != rb {
+				parent[rb] = ra
+			}
+		}
+		i = j
+	}
+
+	// Regions of one file covering mostly the same tokens are one copy
+	// reached from different seeds, e.g. extended further by a neighbour
+	sorted := make([]region, 0, len(regions))
+	for r := range regions {
+		sorted = append(sorted, r)
+	}
+	sortRegions(sorted)
+	for i, a := range sorted {
+		for _, b := range sorted[i+1:] {
+			if b.file != a.file || b.start >= a.end {
+				break
+			}
+			if a.sameCopy(b) {
+				if ra, rb := find(regions[a]), find(regions[b]); ra != rb {
+					parent[rb] = ra
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]region)
+	for r, i := range regions {
+		root := find(i)
+		members[root] = append(members[root], r)
+	}
+
+	var groups []Group
+	for _, rs := range members {
+		if g, ok := x.group(rs); ok {
+			groups = append(groups, g)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].less(groups[j]) })
+	return groups
+}
+
+// less orders groups by their locations, then longest first
+func (g Group) less(o Group) bool {
+	for i := 0; i < len(g.Locations) && i < len(o.Locations); i++ {
+		a, b := g.Locations[i], o.Locations[i]
+		switch {
+		case a.Path != b.Path:
+			return a.Path < b.Path
+		case a.StartLine != b.StartLine:
+			return a.StartLine < b.StartLine
+		case a.EndLine != b.EndLine:
+			return a.EndLine > b.EndLine
+		}
+	}
+	if len(g.Locations) != len(o.Locations) {
+		return len(g.Locations) > len(o.Locations)
+	}
+	return g.Tokens > o.Tokens
+}
+
+// covered reports whether pos lies inside one of rs
+func covered(rs []region, pos int) bool {
+	for _, r := range rs {
+		if r.start <= pos && pos < r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// extend verifies that the runs at two positions match and grows the match
+// in both directions. Matches shorter than MinTokens, from hash collisions,
+// or overlapping themselves are rejected, but still returned.
+func (x *Index) extend(fa, pa, fb, pb int) (region, region, bool) {
+	ta, tb := x.files[fa].tokens, x.files[fb].tokens
+	start := 0
+	for pa+start > 0 && pb+start > 0 && ta[pa+start-1] == tb[pb+start-1] {
+		start--
+	}
+	end := 0
+	for pa+end < len(ta) && pb+end < len(tb) && ta[pa+end] == tb[pb+end] {
+		end++
+	}
+
+	a := region{fa, pa + start, pa + end}
+	b := region{fb, pb + start, pb + end}
+	ok := end-start >= x.MinTokens && !(fa == fb && a.start < b.end && b.start < a.end)
+	return a, b, ok
+}
+
+// sameCopy reports whether two regions of one file overlap by at least
+// half of each
+func (r region) sameCopy(o region) bool {
+	overlap := min(r.end, o.end) - max(r.start, o.start)
+	return r.file == o.file && 2*overlap >= r.end-r.start && 2*overlap >= o.end-o.start
+}
+
+// sortRegions orders regions by file and start, longest first
+func sortRegions(rs []region) {
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].file != rs[j].file {
+			return rs[i].file < rs[j].file
+		}
+		if rs[i].start != rs[j].start {
+			return rs[i].start < rs[j].start
+		}
+		return rs[i].end > rs[j].end
+	})
+}
+
+// group turns the regions of one clone into locations. Of the regions
+// overlapping in one file, only the shortest is kept: it is the part that
+// matches the most other copies.
+func (x *Index) group(rs []region) (Group, bool) {
+	sortRegions(rs)
+
+	var kept []region
+	for _, r := range rs {
+		if n := len(kept); n > 0 && kept[n-1].file == r.file && r.start < kept[n-1].end {
+			if r.end-r.start < kept[n-1].end-kept[n-1].start {
+				kept[n-1] = r
+			}
+			continue
+		}
+		kept = append(kept, r)
+	}
+
+	g := Group{Tokens: -1}
+	for _, r := range kept {
+		f := x.files[r.file]
+		g.Locations = append(g.Locations, Location{
+			Path:      f.path,
+			StartLine: f.line(r.start),
+			EndLine:   f.line(r.end - 1),
+		})
+		if n := r.end - r.start; g.Tokens < 0 || n < g.Tokens {
+			g.Tokens = n
+		}
+	}
+	return g, len(g.Locations) > 1
+}
+
+// rollingHash hashes a run of token hashes as a polynomial in rollBase
+func rollingHash(tokens []uint32) uint64 {
+	var h uint64
+	for _, t := range tokens {
+		h = h*rollBase + uint64(t)
+	}
+	return h
+}
+
+// fingerprint is a winnowed k-token run
+type fingerprint struct {
+	pos  int
+	hash uint64
+}
+
+// winnow returns the k-token runs whose hash is the minimum of some window
+// of w consecutive runs, rolling the hash along the tokens
+func winnow(tokens []uint32, k, w int) []fingerprint {
+	n := len(tokens) - k + 1
+	if n <= 0 {
+		return nil
+	}
+
+	// rollBase^(k-1), to remove the token leaving the run
+	var top uint64 = 1
+	for i := 1; i < k; i++ {
+		top *= rollBase
+	}
+
+	hashes := make([]uint64, n)
+	hashes[0] = rollingHash(tokens[:k])
+	for i := 1; i < n; i++ {
+		hashes[i] = (hashes[i-1]-uint64(tokens[i-1])*top)*rollBase + uint64(tokens[i+k-1])
+	}
+
+	var picked []fingerprint
+	prev := -1
+	for start := 0; start < max(1, n-w+1); start++ {
+		best := start
+		for i := start; i < min(start+w, n); i++ {
+			if hashes[i] <= hashes[best] {
+				best = i // The rightmost minimum, so the next window keeps it
+			}
+		}
+		if best != prev {
+			picked = append(picked, fingerprint{best, hashes[best]})
+			prev = best
+		}
+	}
+	return picked
+}
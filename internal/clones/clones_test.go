@@ -0,0 +1,138 @@
+package clones
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// body returns n lines of distinct statements, named after prefix
+func body(prefix string, n int) []string {
+	var lines []string
+	for i := 0; i < n; i++ {
+		lines = append(lines, fmt.Sprintf("\t%s%d := compute(%q, %d) + offset", prefix, i, prefix, i))
+	}
+	return lines
+}
+
+func concat(parts ...[]string) []string {
+	var lines []string
+	for _, p := range parts {
+		lines = append(lines, p...)
+	}
+	return lines
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"x := f(a, \"b c\")", []string{"x", ":", "=", "f", "(", "a", ",", `"b c"`, ")"}},
+		{"\tif n>=10 {", []string{"if", "n", ">", "=", "10", "{"}},
+		{`s := 'it\'s'`, []string{"s", ":", "=", `'it\'s'`}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tokenize(tt.line), tt.line)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	shared := body("shared", 12) // 12 lines of 11 tokens
+	x := NewIndex(50)
+	assert.True(t, x.Add("b.go", concat(body("b", 3), shared)))
+	assert.True(t, x.Add("a.go", concat(shared, body("a", 5))))
+	assert.True(t, x.Add("c.go", concat(body("c", 2), []string{""}, shared)))
+	assert.True(t, x.Add("d.go", body("d", 20)))
+	assert.False(t, x.Add("tiny.go", []string{"x := 1"}))
+
+	groups := x.Groups()
+	if !assert.Len(t, groups, 1) {
+		return
+	}
+	assert.Equal(t, 12*11, groups[0].Tokens)
+	assert.Equal(t, []Location{
+		{Path: "a.go", StartLine: 1, EndLine: 12},
+		{Path: "b.go", StartLine: 4, EndLine: 15},
+		{Path: "c.go", StartLine: 4, EndLine: 15},
+	}, groups[0].Locations)
+
+	x.Reset()
+	assert.Empty(t, x.Groups())
+}
+
+func TestGroupsIgnoreWhitespaceAndShortRuns(t *testing.T) {
+	shared := body("shared", 12)
+	spaced := make([]string, len(shared))
+	for i, line := range shared {
+		spaced[i] = strings.ReplaceAll(line, " ", "  ")
+	}
+
+	x := NewIndex(50)
+	x.Add("a.go", shared)
+	x.Add("b.go", spaced)
+	assert.Len(t, x.Groups(), 1, "whitespace doesn't matter")
+
+	x = NewIndex(200)
+	x.Add("a.go", concat(shared, body("a", 10)))
+	x.Add("b.go", concat(shared, body("b", 10)))
+	assert.Empty(t, x.Groups(), "shorter than MinTokens")
+}
+
+func TestGroupsWithinOneFile(t *testing.T) {
+	shared := body("shared", 12)
+	x := NewIndex(50)
+	x.Add("a.go", concat(shared, body("a", 3), shared))
+	x.Add("b.go", concat(body("same", 4), body("same", 4), body("same", 4)))
+
+	groups := x.Groups()
+	if !assert.Len(t, groups, 1, "a run repeated back to back only matches itself shifted") {
+		return
+	}
+	assert.Equal(t, []Location{
+		{Path: "a.go", StartLine: 1, EndLine: 12},
+		{Path: "a.go", StartLine: 16, EndLine: 27},
+	}, groups[0].Locations)
+}
+
+func TestWinnowKeepsEveryWindow(t *testing.T) {
+	tokens := make([]uint32, 200)
+	for i := range tokens {
+		tokens[i] = uint32(i * 7919 % 251)
+	}
+	k, w := 10, 8
+	fps := winnow(tokens, k, w)
+
+	prev := -1
+	for _, fp := range fps {
+		assert.Greater(t, fp.pos, prev)
+		assert.Equal(t, rollingHash(tokens[fp.pos:fp.pos+k]), fp.hash)
+		prev = fp.pos
+	}
+	for start := 0; start+w <= len(tokens)-k+1; start++ {
+		found := false
+		for _, fp := range fps {
+			found = found || fp.pos >= start && fp.pos < start+w
+		}
+		assert.True(t, found, "window at %d has no fingerprint", start)
+	}
+}
+
+// BenchmarkGroupsVendoredCopies groups 40 copies of one 5,000-line file,
+// as in a repository vendoring the same package several times
+func BenchmarkGroupsVendoredCopies(b *testing.B) {
+	src := body("v", 5000)
+	for i := 0; i < b.N; i++ {
+		x := NewIndex(0)
+		for f := 0; f < 40; f++ {
+			x.Add(fmt.Sprintf("vendor/m%d/m.go", f), src)
+		}
+		groups := x.Groups()
+		if len(groups) != 1 || len(groups[0].Locations) != 40 {
+			b.Fatalf("got %d groups", len(groups))
+		}
+	}
+}
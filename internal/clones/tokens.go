@@ -0,0 +1,40 @@
+package clones
+
+// tokenize splits a line of code into identifiers, numbers, string
+// literals and single punctuation characters, skipping whitespace
+func tokenize(line string) []string {
+	var tokens []string
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case isIdentByte(c):
+			j := i + 1
+			for j < len(line) && isIdentByte(line[j]) {
+				j++
+			}
+			tokens = append(tokens, line[i:j])
+			i = j
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			for j < len(line) && line[j] != c {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(line))
+			tokens = append(tokens, line[i:j])
+			i = j
+		default:
+			tokens = append(tokens, line[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse(strings.NewReader(`{"markers": [
+		{"name": "OPTIMIZE", "severity": 2, "category": "performance"},
+		{"name": "DEBT", "pattern": "@debt\\b", "languages": ["java"]},
+		{"name": "XXX", "disabled": true}
+	]}`))
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Markers, 3)
+	assert.Equal(t, "@debt\\b", cfg.Markers[1].Pattern)
+	assert.True(t, cfg.Markers[2].Disabled)
+
+	cfg, err = Parse(strings.NewReader(""))
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Markers)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{`{"markers": [{"name": "BAD", "pattern": "(unclosed"}]}`, `marker "BAD": invalid pattern "(unclosed"`},
+		{`{"markers": [{"name": "X", "severity": 9}]}`, "severity 9 is outside 1-5"},
+		{`{"markers": [{"name": ""}]}`, "must be a single non-empty word"},
+		{`{"markers": [{"name": "A"}, {"name": "a"}]}`, "defined more than once"},
+		{`{"markers": [{"name": "A", "languages": ["klingon"]}]}`, `unknown language "klingon"`},
+		{`{"markers": [{"name": "A", "sevrity": 2}]}`, "unknown field"},
+		{`{"duplication": {"min_tokens": 5}}`, "min_tokens 5 is below 20"},
+		{`{"secrets": {"allow": ["[a-"]}}`, `secrets: invalid allow pattern "[a-"`},
+		{`{"secrets": {"paths": ["[x"]}}`, `secrets: invalid path glob "[x"`},
+		{`{"markers": [`, "invalid config"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.config))
+		if assert.Error(t, err, tt.config) {
+			assert.Contains(t, err.Error(), tt.err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := Load(dir)
+	assert.NoError(t, err, "a missing file is not an error")
+	assert.Empty(t, cfg.Markers)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(`{"markers": [{"name": "X", "pattern": "["}]}`), 0644))
+	_, err = Load(dir)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), FileName)
+	}
+}
@@ -0,0 +1,426 @@
+// Package deps finds debt in dependency manifests: go.mod, package.json,
+// requirements.txt, Cargo.toml and Gemfile. Everything is decided from the
+// files themselves, without resolving versions against a registry.
+package deps
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tech-debt-collector/internal/models"
+)
+
+// Types of dependency debt
+const (
+	TypeLocalDependency    = "LOCAL_DEPENDENCY"    // Resolved from a local path
+	TypeForkedDependency   = "FORKED_DEPENDENCY"   // Replaced by another module, usually a fork
+	TypeGitDependency      = "GIT_DEPENDENCY"      // Fetched from a git URL instead of a registry
+	TypeUntaggedDependency = "UNTAGGED_DEPENDENCY" // Pinned to a commit rather than a release
+	TypeUnpinnedDependency = "UNPINNED_DEPENDENCY" // Any version, or no upper bound
+	TypeDuplicateMajor     = "DUPLICATE_MAJOR_VERSION"
+	TypeUnusedDependency   = "UNUSED_DEPENDENCY"  // Required by go.mod but never imported
+	TypeMissingDependency  = "MISSING_DEPENDENCY" // Imported but not required by go.mod
+)
+
+// severities of each type
+var severities = map[string]int{
+	TypeLocalDependency:    3,
+	TypeForkedDependency:   3,
+	TypeGitDependency:      3,
+	TypeUntaggedDependency: 2,
+	TypeUnpinnedDependency: 3,
+	TypeDuplicateMajor:     3,
+	TypeUnusedDependency:   2,
+	TypeMissingDependency:  4,
+}
+
+// kind classifies how a dependency is specified
+type kind int
+
+const (
+	kindVersion  kind = iota // A released version or range: fine
+	kindLocal                // A path on disk
+	kindFork                 // Another module or package
+	kindGit                  // A git URL or hosting shorthand
+	kindUntagged             // A commit, such as a Go pseudo-version
+	kindUnpinned             // A wildcard, a tag such as latest, or no upper bound
+)
+
+var kindTypes = map[kind]string{
+	kindLocal:    TypeLocalDependency,
+	kindFork:     TypeForkedDependency,
+	kindGit:      TypeGitDependency,
+	kindUntagged: TypeUntaggedDependency,
+	kindUnpinned: TypeUnpinnedDependency,
+}
+
+// dependency is one entry of a manifest
+type dependency struct {
+	name     string // Name in the registry, e.g. a Go module path
+	base     string // Name without a major version suffix, as in Go's /v2
+	spec     string // Version, constraint or source as written
+	major    string // Major version the spec selects, "" if unknown
+	kind     kind
+	line     int
+	indirect bool // Go: only needed by other dependencies
+}
+
+// manifest is a parsed dependency manifest
+type manifest struct {
+	path      string
+	ecosystem string
+	deps      []dependency
+
+	// Go modules only
+	module     string
+	moduleLine int
+	requires   []string // Every required module path, direct or not
+	tools      []string // Module paths named by tool directives
+}
+
+// goFile is the imports of one Go source file
+type goFile struct {
+	path    string
+	imports []string
+}
+
+// Manifests collects manifests, and the imports of Go files so go.mod can
+// be checked against them. Files may be added from several goroutines at
+// once and are checked together by Findings.
+type Manifests struct {
+	// Partial means only some of the repository's files are added, as when
+	// scanning a change set. Modules then can't be known to be unused.
+	Partial bool
+
+	mu        sync.Mutex
+	manifests []*manifest
+	goFiles   []goFile
+}
+
+// NewManifests creates an empty set of manifests
+func NewManifests() *Manifests {
+	return &Manifests{}
+}
+
+// Add records a manifest, or the imports of a Go source file, and reports
+// whether the file was one of those
+func (m *Manifests) Add(filename string, data []byte) bool {
+	if strings.HasSuffix(filename, ".go") {
+		f, err := parser.ParseFile(token.NewFileSet(), filename, data, parser.ImportsOnly)
+		if err != nil {
+			return false
+		}
+		gf := goFile{path: filename}
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			gf.imports = append(gf.imports, p)
+		}
+		m.mu.Lock()
+		m.goFiles = append(m.goFiles, gf)
+		m.mu.Unlock()
+		return true
+	}
+
+	parse, ok := parsers[manifestName(filename)]
+	if !ok {
+		return false
+	}
+	mf := parse(splitLines(data))
+	mf.path = filename
+	m.mu.Lock()
+	m.manifests = append(m.manifests, mf)
+	m.mu.Unlock()
+	return true
+}
+
+// Reset forgets the files added so far
+func (m *Manifests) Reset() {
+	m.mu.Lock()
+	m.manifests = nil
+	m.goFiles = nil
+	m.mu.Unlock()
+}
+
+// parsers reads each kind of manifest, by base name
+var parsers = map[string]func(lines []string) *manifest{
+	"go.mod":           parseGoMod,
+	"package.json":     parsePackageJSON,
+	"requirements.txt": parseRequirements,
+	"Cargo.toml":       parseCargoToml,
+	"Gemfile":          parseGemfile,
+}
+
+// IsManifest reports whether a file name is a dependency manifest read by
+// this package. Requirements files may be split, as in requirements-dev.txt.
+func IsManifest(name string) bool {
+	_, ok := parsers[manifestName(name)]
+	return ok
+}
+
+// manifestName maps a file path onto its parser's key
+func manifestName(filename string) string {
+	base := path.Base(filepath.ToSlash(filename))
+	if strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt") {
+		return "requirements.txt"
+	}
+	return base
+}
+
+// Findings checks the manifests added so far, each on its own and against
+// the others, and returns the debt found ordered by path and line
+func (m *Manifests) Findings() []models.DebtItem {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sort.Slice(m.manifests, func(i, j int) bool { return m.manifests[i].path < m.manifests[j].path })
+
+	var items []models.DebtItem
+	for _, mf := range m.manifests {
+		for _, dep := range mf.deps {
+			if t, ok := kindTypes[dep.kind]; ok {
+				items = append(items, newItem(mf.path, dep.line, t, describe(dep)))
+			}
+		}
+		if mf.ecosystem == "go" {
+			items = append(items, m.checkImports(mf)...)
+		}
+	}
+	items = append(items, m.duplicateMajors()...)
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].FilePath != items[j].FilePath {
+			return items[i].FilePath < items[j].FilePath
+		}
+		return items[i].LineNumber < items[j].LineNumber
+	})
+	return items
+}
+
+func newItem(path string, line int, typ, message string) models.DebtItem {
+	return models.DebtItem{
+		FilePath:   path,
+		LineNumber: line,
+		Type:       typ,
+		Category:   models.CategoryDependency,
+		Message:    message,
+		Severity:   severities[typ],
+	}
+}
+
+// describe explains what is wrong with a dependency's spec
+func describe(dep dependency) string {
+	switch dep.kind {
+	case kindLocal:
+		return fmt.Sprintf("%s is resolved from the local path %s", dep.name, dep.spec)
+	case kindFork:
+		return fmt.Sprintf("%s is replaced by %s", dep.name, dep.spec)
+	case kindGit:
+		return fmt.Sprintf("%s is fetched from git: %s", dep.name, dep.spec)
+	case kindUntagged:
+		return fmt.Sprintf("%s is pinned to an untagged commit: %s", dep.name, dep.spec)
+	case kindUnpinned:
+		if dep.spec == "" {
+			return fmt.Sprintf("%s has no version constraint", dep.name)
+		}
+		return fmt.Sprintf("%s accepts any version: %s", dep.name, dep.spec)
+	}
+	return dep.name
+}
+
+// duplicateMajors reports packages required at several major versions,
+// within one manifest or across the repository. Each is reported once, at
+// its first occurrence, with every occurrence as a location.
+func (m *Manifests) duplicateMajors() []models.DebtItem {
+	type occurrence struct {
+		path string
+		dep  dependency
+	}
+	byName := make(map[string][]occurrence)
+	var names []string
+	for _, mf := range m.manifests {
+		for _, dep := range mf.deps {
+			if dep.major == "" || dep.indirect {
+				continue
+			}
+			key := mf.ecosystem + "\x00" + dep.base
+			if _, ok := byName[key]; !ok {
+				names = append(names, key)
+			}
+			byName[key] = append(byName[key], occurrence{mf.path, dep})
+		}
+	}
+
+	var items []models.DebtItem
+	for _, key := range names {
+		occs := byName[key]
+		var majors, where []string
+		seen := make(map[string]bool)
+		for _, o := range occs {
+			if !seen[o.dep.major] {
+				seen[o.dep.major] = true
+				majors = append(majors, o.dep.major)
+			}
+			where = append(where, fmt.Sprintf("%s:%d", o.path, o.dep.line))
+		}
+		if len(majors) < 2 {
+			continue
+		}
+		sort.Strings(majors)
+
+		item := newItem(occs[0].path, occs[0].dep.line, TypeDuplicateMajor,
+			fmt.Sprintf("%s is required at major versions %s in %s", occs[0].dep.base, strings.Join(majors, ", "), strings.Join(where, ", ")))
+		for _, o := range occs {
+			item.Locations = append(item.Locations, models.Location{FilePath: o.path, StartLine: o.dep.line, EndLine: o.dep.line})
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// checkImports compares a go.mod with the imports of the Go files of its
+// module: those below its directory and not in a nested module, testdata or
+// vendor. Modules required but never imported are only reported when the
+// module's files were seen and the scan isn't Partial, since a partial scan
+// would make them all look unused.
+func (m *Manifests) checkImports(mf *manifest) []models.DebtItem {
+	root := path.Dir(filepath.ToSlash(mf.path))
+	var nested []string
+	for _, other := range m.manifests {
+		dir := path.Dir(filepath.ToSlash(other.path))
+		if other.ecosystem == "go" && dir != root && within(dir, root) {
+			nested = append(nested, dir)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, tool := range mf.tools {
+		used[tool] = true
+	}
+	missing := make(map[string]string) // Import root to the first file importing it
+	var missingOrder []string
+	seenFiles := false
+
+	sort.Slice(m.goFiles, func(i, j int) bool { return m.goFiles[i].path < m.goFiles[j].path })
+	for _, gf := range m.goFiles {
+		dir := path.Dir(filepath.ToSlash(gf.path))
+		if !within(dir, root) || excludedDir(dir, root) {
+			continue
+		}
+		inNested := false
+		for _, n := range nested {
+			inNested = inNested || within(dir, n)
+		}
+		if inNested {
+			continue
+		}
+		seenFiles = true
+
+		for _, imp := range gf.imports {
+			if isStdlib(imp) || mf.module != "" && within(imp, mf.module) {
+				continue
+			}
+			if mod := requiredBy(mf.requires, imp); mod != "" {
+				used[mod] = true
+				continue
+			}
+			r := importRoot(imp)
+			if _, ok := missing[r]; !ok {
+				missing[r] = gf.path
+				missingOrder = append(missingOrder, r)
+			}
+		}
+	}
+
+	var items []models.DebtItem
+	for _, r := range missingOrder {
+		items = append(items, newItem(mf.path, mf.moduleLine, TypeMissingDependency,
+			fmt.Sprintf("%s is imported by %s but not required", r, missing[r])))
+	}
+	if !seenFiles || m.Partial {
+		return items
+	}
+	for _, dep := range mf.deps {
+		if dep.indirect || dep.kind == kindLocal || dep.kind == kindFork || used[dep.name] {
+			continue
+		}
+		items = append(items, newItem(mf.path, dep.line, TypeUnusedDependency,
+			fmt.Sprintf("%s is required but not imported by any package", dep.name)))
+	}
+	return items
+}
+
+// requiredBy returns the required module providing an import: the longest
+// module path that is a prefix of it
+func requiredBy(requires []string, imp string) string {
+	best := ""
+	for _, mod := range requires {
+		if within(imp, mod) && len(mod) > len(best) {
+			best = mod
+		}
+	}
+	return best
+}
+
+// importRoot guesses the module of an import that no requirement provides,
+// e.g. github.com/owner/repo for a package deep inside it
+func importRoot(imp string) string {
+	parts := strings.Split(imp, "/")
+	n := 2
+	switch parts[0] {
+	case "github.com", "gitlab.com", "bitbucket.org", "golang.org":
+		n = 3
+	}
+	if len(parts) > n && isMajorSuffix(parts[n]) {
+		n++
+	}
+	return strings.Join(parts[:min(n, len(parts))], "/")
+}
+
+// isStdlib reports whether an import path belongs to the standard library,
+// whose first element has no dot
+func isStdlib(imp string) bool {
+	first, _, _ := strings.Cut(imp, "/")
+	return !strings.Contains(first, ".")
+}
+
+// within reports whether a slash-separated path is root or below it
+func within(p, root string) bool {
+	if root == "." {
+		return !path.IsAbs(p) && p != ".." && !strings.HasPrefix(p, "../")
+	}
+	return p == root || strings.HasPrefix(p, root+"/")
+}
+
+// excludedDir reports whether dir, below root, is in a directory the go
+// command ignores for dependencies
+func excludedDir(dir, root string) bool {
+	rel := strings.TrimPrefix(strings.TrimPrefix(dir, root), "/")
+	if root == "." {
+		rel = dir
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if part == "testdata" || part == "vendor" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines splits data into lines without their line endings
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,85 @@
+package deps
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func findings(files map[string]string) []string {
+	m := NewManifests()
+	for path, src := range files {
+		m.Add(path, []byte(src))
+	}
+	var got []string
+	for _, item := range m.Findings() {
+		got = append(got, fmt.Sprintf("%s:%d %s %s", item.FilePath, item.LineNumber, item.Type, item.Message))
+	}
+	return got
+}
+
+func TestFindingsCheckGoImports(t *testing.T) {
+	got := findings(map[string]string{
+		"go.mod":               "module example.com/app\n\ngo 1.21\n\nrequire (\n\tgithub.com/go-testify/assert v1.8.4\n\tgithub.com/joho/godotenv v1.5.1\n\tgolang.org/x/sync v0.5.0 // indirect\n)\n",
+		"main.go":              "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/internal/x\"\n\t\"github.com/joho/godotenv\"\n)\n",
+		"internal/x/x_test.go": "package x\n\nimport \"github.com/stretchr/testify/assert\"\n",
+		"internal/x/y_test.go": "package x\n\nimport \"github.com/stretchr/testify/require\"\n",
+		"testdata/fixture.go":  "package fixture\n\nimport \"example.org/unrelated\"\n",
+		"tools/go.mod":         "module example.com/tools\n",
+		"tools/main.go":        "package main\n\nimport \"github.com/other/dep\"\n",
+		"broken.go":            "package",
+	})
+	assert.Equal(t, []string{
+		"go.mod:1 MISSING_DEPENDENCY github.com/stretchr/testify is imported by internal/x/x_test.go but not required",
+		"go.mod:6 UNUSED_DEPENDENCY github.com/go-testify/assert is required but not imported by any package",
+		"tools/go.mod:1 MISSING_DEPENDENCY github.com/other/dep is imported by tools/main.go but not required",
+	}, got)
+}
+
+func TestFindingsWithoutGoFiles(t *testing.T) {
+	got := findings(map[string]string{
+		"go.mod": "module example.com/app\n\nrequire github.com/a/b v1.0.0\nreplace github.com/a/b => ./b\n",
+	})
+	assert.Equal(t, []string{
+		"go.mod:4 LOCAL_DEPENDENCY github.com/a/b is resolved from the local path ./b",
+	}, got, "unused modules need the module's Go files")
+}
+
+func TestFindingsPartialScan(t *testing.T) {
+	m := NewManifests()
+	m.Partial = true
+	m.Add("go.mod", []byte("module example.com/app\n\nrequire (\n\tgithub.com/joho/godotenv v1.5.1\n\tgithub.com/x/y v1.0.0\n)\n"))
+	m.Add("cmd/app/main.go", []byte("package main\n\nimport \"github.com/other/dep\"\n"))
+	var got []string
+	for _, item := range m.Findings() {
+		got = append(got, item.Type+" "+item.Message)
+	}
+	assert.Equal(t, []string{
+		"MISSING_DEPENDENCY github.com/other/dep is imported by cmd/app/main.go but not required",
+	}, got, "modules imported by files outside the scan aren't unused")
+}
+
+func TestFindingsDuplicateMajors(t *testing.T) {
+	m := NewManifests()
+	m.Add("web/package.json", []byte(`{"dependencies": {"lodash": "^4.17.21", "react": "^18.2.0"}}`))
+	m.Add("admin/package.json", []byte("{\n  \"dependencies\": {\n    \"lodash\": \"^3.10.1\",\n    \"react\": \"18.1.0\"\n  }\n}\n"))
+	m.Add("go.mod", []byte("module a\n\nrequire (\n\tgithub.com/x/y v1.0.0\n\tgithub.com/x/y/v2 v2.1.0\n)\n"))
+	m.Add("Cargo.toml", []byte("[dependencies]\nlodash = \"2\"\n"))
+
+	items := m.Findings()
+	if !assert.Len(t, items, 2) {
+		return
+	}
+	assert.Equal(t, TypeDuplicateMajor, items[0].Type)
+	assert.Equal(t, "admin/package.json", items[0].FilePath)
+	assert.Equal(t, 3, items[0].LineNumber)
+	assert.Equal(t, "lodash is required at major versions 3, 4 in admin/package.json:3, web/package.json:1", items[0].Message)
+	assert.Len(t, items[0].Locations, 2)
+	assert.Equal(t, "go.mod", items[1].FilePath)
+	assert.Equal(t, "github.com/x/y is required at major versions v1, v2 in go.mod:4, go.mod:5", items[1].Message)
+	assert.Equal(t, 3, items[1].Severity)
+
+	m.Reset()
+	assert.Empty(t, m.Findings())
+}
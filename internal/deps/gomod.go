@@ -0,0 +1,122 @@
+package deps
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pseudoVersionRe matches Go pseudo-versions, which name a commit rather
+// than a tag: v0.0.0-20230102150405-abcdef123456 and its variants
+var pseudoVersionRe = regexp.MustCompile(`^v\d+\.\d+\.\d+-(?:[0-9A-Za-z.-]+\.)?(?:0\.)?\d{14}-[0-9a-f]{12}(?:\+incompatible)?$`)
+
+// parseGoMod reads the module, require, replace and tool directives of a
+// go.mod file. Required modules replaced by a local path or another module
+// are reported through their replace directive only.
+func parseGoMod(lines []string) *manifest {
+	mf := &manifest{ecosystem: "go"}
+	replaced := make(map[string]bool)
+	block := ""
+
+	for i, raw := range lines {
+		line, comment, _ := strings.Cut(raw, "//")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		for j, f := range fields {
+			if unquoted, err := strconv.Unquote(f); err == nil {
+				fields[j] = unquoted
+			}
+		}
+
+		verb := block
+		switch {
+		case fields[0] == ")":
+			block = ""
+			continue
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case block == "":
+			verb, fields = fields[0], fields[1:]
+		}
+
+		switch verb {
+		case "module":
+			if len(fields) > 0 {
+				mf.module, mf.moduleLine = fields[0], i+1
+			}
+		case "tool":
+			if len(fields) > 0 {
+				mf.tools = append(mf.tools, fields[0])
+			}
+		case "require":
+			if len(fields) < 2 {
+				continue
+			}
+			dep := goDependency(fields[0], fields[1], i+1)
+			dep.indirect = strings.TrimSpace(comment) == "indirect" || strings.HasPrefix(strings.TrimSpace(comment), "indirect;")
+			if pseudoVersionRe.MatchString(dep.spec) && !dep.indirect {
+				dep.kind = kindUntagged
+			}
+			mf.requires = append(mf.requires, dep.name)
+			mf.deps = append(mf.deps, dep)
+		case "replace":
+			old, target, ok := strings.Cut(strings.Join(fields, " "), "=>")
+			oldFields, newFields := strings.Fields(old), strings.Fields(target)
+			if !ok || len(oldFields) == 0 || len(newFields) == 0 {
+				continue
+			}
+			dep := dependency{name: oldFields[0], base: oldFields[0], spec: strings.Join(newFields, " "), line: i + 1}
+			switch {
+			case isLocalPath(newFields[0]):
+				dep.kind = kindLocal
+			case newFields[0] != oldFields[0]:
+				dep.kind = kindFork
+			default:
+				continue // The same module at another version
+			}
+			replaced[dep.name] = true
+			mf.deps = append(mf.deps, dep)
+		}
+	}
+
+	for i, dep := range mf.deps {
+		if dep.kind == kindUntagged && replaced[dep.name] {
+			mf.deps[i].kind = kindVersion
+		}
+	}
+	return mf
+}
+
+// goDependency describes a required module. Its major version comes from
+// the path suffix, as in /v2 or gopkg.in/yaml.v3, or else from an
+// +incompatible version; v0 and v1 share a path and are one major.
+func goDependency(modPath, version string, line int) dependency {
+	dep := dependency{name: modPath, base: modPath, spec: version, major: "v1", line: line}
+	if i := strings.LastIndexByte(modPath, '/'); i >= 0 && isMajorSuffix(modPath[i+1:]) {
+		dep.base, dep.major = modPath[:i], modPath[i+1:]
+	} else if strings.HasPrefix(modPath, "gopkg.in/") {
+		if i := strings.LastIndex(modPath, ".v"); i >= 0 {
+			dep.base, dep.major = modPath[:i], modPath[i+1:]
+		}
+	} else if strings.HasSuffix(version, "+incompatible") {
+		major, _, _ := strings.Cut(version, ".")
+		dep.major = major
+	}
+	return dep
+}
+
+// isMajorSuffix reports whether a path element is a major version suffix,
+// v2 or above
+func isMajorSuffix(elem string) bool {
+	n, err := strconv.Atoi(strings.TrimPrefix(elem, "v"))
+	return strings.HasPrefix(elem, "v") && err == nil && n >= 2
+}
+
+// isLocalPath reports whether a replacement or source is a file path
+func isLocalPath(p string) bool {
+	return strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || strings.HasPrefix(p, "/") ||
+		p == "." || p == ".." || len(p) > 2 && p[1] == ':' && (p[2] == '\\' || p[2] == '/')
+}
@@ -0,0 +1,436 @@
+package deps
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// npmSections are the package.json objects listing dependencies. Peer
+// dependencies are left out: their ranges are broad on purpose.
+var npmSections = map[string]bool{
+	"dependencies":         true,
+	"devDependencies":      true,
+	"optionalDependencies": true,
+}
+
+// parsePackageJSON reads the dependency objects of a package.json. A file
+// that isn't valid JSON yields no dependencies.
+func parsePackageJSON(lines []string) *manifest {
+	mf := &manifest{ecosystem: "npm"}
+	data := strings.Join(lines, "\n")
+	lineAt := func(offset int64) int { return 1 + strings.Count(data[:offset], "\n") }
+
+	dec := json.NewDecoder(strings.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return mf
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return mf
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return mf
+		}
+		if name, _ := key.(string); !npmSections[name] {
+			continue
+		}
+
+		start := dec.InputOffset() - int64(len(raw))
+		var section map[string]string
+		if json.Unmarshal(raw, &section) != nil {
+			continue
+		}
+		sub := json.NewDecoder(strings.NewReader(string(raw)))
+		sub.Token() // The opening brace
+		for sub.More() {
+			tok, err := sub.Token()
+			if err != nil {
+				break
+			}
+			name, _ := tok.(string)
+			line := lineAt(start + sub.InputOffset())
+			var discard json.RawMessage
+			if sub.Decode(&discard) != nil {
+				break
+			}
+			mf.deps = append(mf.deps, npmDependency(name, section[name], line))
+		}
+	}
+	return mf
+}
+
+var (
+	npmGitPrefixes = []string{"git+", "git://", "github:", "gitlab:", "bitbucket:", "gist:"}
+	npmShorthandRe = regexp.MustCompile(`^[\w.-]+/[\w.-]+(?:#.*)?$`) // owner/repo on GitHub
+	distTagRe      = regexp.MustCompile(`^[a-zA-Z-]+$`)              // latest, next, beta...
+)
+
+// npmDependency classifies one entry of a package.json dependency object
+func npmDependency(name, spec string, line int) dependency {
+	// An alias installs another package: "npm:lodash@^4"
+	if alias, ok := strings.CutPrefix(spec, "npm:"); ok {
+		if i := strings.LastIndexByte(alias, '@'); i > 0 {
+			name, spec = alias[:i], alias[i+1:]
+		} else {
+			name, spec = alias, ""
+		}
+	}
+	dep := dependency{name: name, base: name, spec: spec, line: line}
+
+	s := strings.TrimSpace(spec)
+	switch {
+	case strings.HasPrefix(s, "file:"), strings.HasPrefix(s, "link:"), strings.HasPrefix(s, "portal:"), isLocalPath(s):
+		dep.kind = kindLocal
+	case strings.HasPrefix(s, "workspace:"):
+		// A package of the same monorepo
+	case hasAnyPrefix(s, npmGitPrefixes), strings.HasSuffix(s, ".git"), npmShorthandRe.MatchString(s):
+		dep.kind = kindGit
+	case strings.Contains(s, "://"):
+		// A tarball URL
+	case s == "" || s == "*" || s == "x" || s == "X" || distTagRe.MatchString(s) || unbounded(s):
+		dep.kind = kindUnpinned
+	default:
+		dep.major = semverMajor(s)
+	}
+	return dep
+}
+
+var (
+	requirementRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(.*)$`)
+	eggRe         = regexp.MustCompile(`#(?:.*&)?egg=([\w.-]+)`)
+	vcsPrefixes   = []string{"git+", "hg+", "svn+", "bzr+"}
+)
+
+// parseRequirements reads a pip requirements file. Requirements without an
+// exact or upper bound are unpinned, since these files are often the only
+// record of what was installed.
+func parseRequirements(lines []string) *manifest {
+	mf := &manifest{ecosystem: "pypi"}
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		text := lines[i]
+		for strings.HasSuffix(text, "\\") && i+1 < len(lines) {
+			i++
+			text = strings.TrimSuffix(text, "\\") + " " + lines[i]
+		}
+		if j := strings.Index(text, " #"); j >= 0 {
+			text = text[:j]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if editable, ok := cutOption(text, "-e", "--editable"); ok {
+			text = editable
+		} else if strings.HasPrefix(text, "-") {
+			continue // Other options, such as -r other.txt or --index-url
+		}
+		if dep, ok := requirement(text, lineNo); ok {
+			mf.deps = append(mf.deps, dep)
+		}
+	}
+	return mf
+}
+
+// requirement classifies one requirement: a path, a URL, or a name with
+// version specifiers
+func requirement(text string, line int) (dependency, bool) {
+	source := text
+	name := ""
+	if n, url, ok := strings.Cut(text, " @ "); ok {
+		name, source = strings.TrimSpace(n), strings.TrimSpace(url)
+	}
+	if m := eggRe.FindStringSubmatch(source); m != nil && name == "" {
+		name = m[1]
+	}
+
+	dep := dependency{spec: source, line: line}
+	switch {
+	case isLocalPath(source), strings.HasPrefix(source, "file:"):
+		dep.kind = kindLocal
+	case hasAnyPrefix(source, vcsPrefixes):
+		dep.kind = kindGit
+	case strings.Contains(source, "://"):
+		// An archive URL
+	default:
+		m := requirementRe.FindStringSubmatch(text)
+		if m == nil {
+			return dependency{}, false
+		}
+		name = m[1]
+		spec, _, _ := strings.Cut(m[2], ";")  // Drop environment markers
+		spec, _, _ = strings.Cut(spec, " --") // and options such as --hash
+		dep.spec = strings.TrimSpace(spec)
+		switch {
+		case dep.spec == "", strings.Contains(dep.spec, "*"):
+			dep.kind = kindUnpinned
+		case strings.HasPrefix(dep.spec, "==") || strings.HasPrefix(dep.spec, "~="):
+			dep.major = semverMajor(strings.TrimLeft(dep.spec, "=~"))
+		case !strings.Contains(dep.spec, "<"):
+			dep.kind = kindUnpinned
+		}
+	}
+
+	if name == "" {
+		name = source
+	}
+	dep.name = name
+	dep.base = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	return dep, true
+}
+
+var (
+	tomlHeaderRe = regexp.MustCompile(`^\[\s*([^\]]+?)\s*\]`)
+	tomlEntryRe  = regexp.MustCompile(`^([\w-]+(?:\.[\w-]+)?)\s*=\s*(.+)$`)
+	tomlPairRe   = regexp.MustCompile(`([\w-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|(true|false))`)
+)
+
+// parseCargoToml reads the dependency tables of a Cargo.toml, including
+// target-specific, workspace and patch tables, in the inline form
+// (serde = { version = "1" }) and the table form ([dependencies.serde])
+func parseCargoToml(lines []string) *manifest {
+	mf := &manifest{ecosystem: "cargo"}
+	inDeps := false
+	var table *dependency // The dependency of a [dependencies.name] table
+	var tableAttrs map[string]string
+
+	flush := func() {
+		if table != nil {
+			mf.deps = append(mf.deps, cargoDependency(table.name, tableAttrs, table.line))
+			table = nil
+		}
+	}
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[[") {
+			flush()
+			inDeps = false // An array of tables, such as [[bin]]
+			continue
+		}
+		if m := tomlHeaderRe.FindStringSubmatch(line); m != nil {
+			flush()
+			section, name := m[1], ""
+			if j := strings.LastIndexByte(section, '.'); j >= 0 && isCargoDepsTable(section[:j]) && !strings.ContainsAny(section[:j], `"'`) {
+				section, name = section[:j], strings.Trim(section[j+1:], `"'`)
+			}
+			inDeps = isCargoDepsTable(section)
+			if inDeps && name != "" {
+				table, tableAttrs = &dependency{name: name, line: i + 1}, make(map[string]string)
+			}
+			continue
+		}
+		if !inDeps {
+			continue
+		}
+
+		m := tomlEntryRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if table != nil {
+			for k, v := range tomlPairs(m[1] + " = " + m[2]) {
+				tableAttrs[k] = v
+			}
+			continue
+		}
+
+		name, attr, dotted := strings.Cut(m[1], ".") // serde.version = "1"
+		value := strings.TrimSpace(m[2])
+		var attrs map[string]string
+		switch {
+		case dotted:
+			attrs = tomlPairs(attr + " = " + value)
+		case strings.HasPrefix(value, "{"):
+			attrs = tomlPairs(value)
+		default:
+			attrs = map[string]string{"version": strings.Trim(value, `"'`)}
+		}
+		mf.deps = append(mf.deps, cargoDependency(name, attrs, i+1))
+	}
+	flush()
+	return mf
+}
+
+// isCargoDepsTable reports whether a table header names dependencies
+func isCargoDepsTable(section string) bool {
+	last := section[strings.LastIndexByte(section, '.')+1:]
+	return last == "dependencies" || last == "dev-dependencies" || last == "build-dependencies" ||
+		strings.HasPrefix(section, "patch.") || section == "replace"
+}
+
+// tomlPairs extracts the string and boolean values of an inline table
+func tomlPairs(text string) map[string]string {
+	pairs := make(map[string]string)
+	for _, m := range tomlPairRe.FindAllStringSubmatch(text, -1) {
+		pairs[m[1]] = m[2] + m[3] + m[4]
+	}
+	return pairs
+}
+
+// cargoDependency classifies a dependency from its attributes
+func cargoDependency(name string, attrs map[string]string, line int) dependency {
+	base := name
+	if pkg := attrs["package"]; pkg != "" {
+		base = pkg // A renamed dependency
+	}
+	dep := dependency{name: name, base: base, spec: attrs["version"], line: line}
+	switch {
+	case attrs["workspace"] == "true":
+		// Inherited from the workspace, which is checked on its own
+	case attrs["path"] != "":
+		dep.kind, dep.spec = kindLocal, attrs["path"]
+	case attrs["git"] != "":
+		dep.kind, dep.spec = kindGit, gitSpec(attrs["git"], attrs)
+	case dep.spec == "" || dep.spec == "*" || unbounded(dep.spec):
+		dep.kind = kindUnpinned
+	default:
+		dep.major = semverMajor(dep.spec)
+	}
+	return dep
+}
+
+var (
+	gemRe       = regexp.MustCompile(`^gem\s*\(?\s*["']([^"']+)["']\s*(.*)$`)
+	gemArgRe    = regexp.MustCompile(`^\s*,\s*(?:["']([^"']*)["']|:?(\w+)(?::|\s*=>)\s*["']?([^"',]*)["']?)`)
+	gemSourceRe = regexp.MustCompile(`^(git|github|gitlab|bitbucket|path)\s*\(?\s*["']([^"']+)["'].*\bdo\b`)
+	blockOpenRe = regexp.MustCompile(`\bdo\s*(?:\|[^|]*\|)?$`)
+)
+
+// parseGemfile reads the gem declarations of a Gemfile. Gems without a
+// version are fine, as Gemfile.lock pins them; one bounded only from below
+// is unpinned.
+func parseGemfile(lines []string) *manifest {
+	mf := &manifest{ecosystem: "rubygems"}
+	var blocks []map[string]string // Source of each open do block, nil for others such as group
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if j := strings.Index(line, " #"); j >= 0 {
+			line = strings.TrimSpace(line[:j])
+		}
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case line == "end":
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+			continue
+		case gemSourceRe.MatchString(line):
+			m := gemSourceRe.FindStringSubmatch(line)
+			blocks = append(blocks, map[string]string{m[1]: m[2]})
+			continue
+		}
+
+		m := gemRe.FindStringSubmatch(line)
+		if m == nil {
+			if blockOpenRe.MatchString(line) {
+				blocks = append(blocks, nil)
+			}
+			continue
+		}
+
+		opts := make(map[string]string)
+		for _, b := range blocks {
+			for k, v := range b {
+				opts[k] = v
+			}
+		}
+		var constraints []string
+		rest := m[2]
+		for {
+			arg := gemArgRe.FindStringSubmatch(rest)
+			if arg == nil {
+				break
+			}
+			if arg[2] == "" {
+				constraints = append(constraints, arg[1])
+			} else {
+				opts[arg[2]] = arg[3]
+			}
+			rest = rest[len(arg[0]):]
+		}
+		mf.deps = append(mf.deps, gemDependency(m[1], constraints, opts, i+1))
+	}
+	return mf
+}
+
+// gemDependency classifies a gem from its version constraints and options
+func gemDependency(name string, constraints []string, opts map[string]string, line int) dependency {
+	dep := dependency{name: name, base: name, spec: strings.Join(constraints, ", "), line: line}
+	switch {
+	case opts["path"] != "":
+		dep.kind, dep.spec = kindLocal, opts["path"]
+	case opts["git"] != "":
+		dep.kind, dep.spec = kindGit, gitSpec(opts["git"], opts)
+	case opts["github"] != "", opts["gitlab"] != "", opts["bitbucket"] != "":
+		dep.kind, dep.spec = kindGit, gitSpec(opts["github"]+opts["gitlab"]+opts["bitbucket"], opts)
+	case len(constraints) == 0:
+		// Pinned by Gemfile.lock
+	case len(constraints) == 1 && (constraints[0] == ">= 0" || unbounded(constraints[0])):
+		dep.kind = kindUnpinned
+	default:
+		dep.major = semverMajor(strings.TrimLeft(constraints[0], "~>= "))
+	}
+	return dep
+}
+
+// gitSpec describes a git source and the ref it tracks, if any
+func gitSpec(url string, attrs map[string]string) string {
+	for _, ref := range []string{"rev", "ref", "tag", "branch"} {
+		if attrs[ref] != "" {
+			return url + " " + ref + " " + attrs[ref]
+		}
+	}
+	return url
+}
+
+// unbounded reports whether a constraint only sets a lower bound, as in
+// ">= 1.2", so any future major version satisfies it
+func unbounded(spec string) bool {
+	s := strings.TrimSpace(spec)
+	return (strings.HasPrefix(s, ">") || strings.HasPrefix(s, "!=")) && !strings.Contains(s, "<") && !strings.Contains(s, "||")
+}
+
+// semverMajor returns the major version a version or caret, tilde or exact
+// constraint selects: "^1.2" is 1, "~> 6.1" is 6, and "0.4.2" is 0.4,
+// since 0.x releases break compatibility at each minor version
+func semverMajor(spec string) string {
+	s := strings.TrimLeft(strings.TrimSpace(spec), "^~=v ")
+	if s == "" || s[0] < '0' || s[0] > '9' || strings.ContainsAny(s, " |<>") {
+		return ""
+	}
+	parts := strings.SplitN(s, ".", 3)
+	if parts[0] == "0" && len(parts) > 1 && parts[1] != "" && parts[1][0] >= '0' && parts[1][0] <= '9' {
+		return "0." + strings.TrimRight(parts[1], "-+abcdefghijklmnopqrstuvwxyz")
+	}
+	return strings.TrimRight(parts[0], "-+abcdefghijklmnopqrstuvwxyz")
+}
+
+// cutOption returns the argument of a command-line option given in either
+// of its forms
+func cutOption(text string, forms ...string) (string, bool) {
+	for _, f := range forms {
+		if rest, ok := strings.CutPrefix(text, f); ok && (rest == "" || rest[0] == ' ' || rest[0] == '=') {
+			return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest), "=")), true
+		}
+	}
+	return "", false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
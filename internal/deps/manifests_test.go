@@ -0,0 +1,217 @@
+package deps
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// summarize lists a manifest's dependencies as "line name kind major"
+func summarize(mf *manifest) []string {
+	kinds := map[kind]string{kindVersion: "ok", kindLocal: "local", kindFork: "fork", kindGit: "git", kindUntagged: "untagged", kindUnpinned: "unpinned"}
+	var got []string
+	for _, dep := range mf.deps {
+		got = append(got, strings.TrimSpace(fmt.Sprintf("%d %s %s %s", dep.line, dep.base, kinds[dep.kind], dep.major)))
+	}
+	return got
+}
+
+func TestParseGoMod(t *testing.T) {
+	src := `module example.com/app
+
+go 1.21
+
+require (
+	github.com/a/lib v1.2.3
+	github.com/a/lib/v2 v2.0.1
+	github.com/b/tool v0.0.0-20230102150405-abcdef123456
+	github.com/c/old v1.4.0-rc.1.0.20230102150405-abcdef123456 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+	example.com/local v0.0.0-00010101000000-000000000000
+)
+
+require github.com/d/legacy v3.1.0+incompatible
+
+replace example.com/local => ../local
+
+replace (
+	github.com/a/lib => github.com/me/lib v1.2.4
+	github.com/b/tool v0.0.0-20230102150405-abcdef123456 => github.com/b/tool v0.1.0
+)
+
+tool golang.org/x/tools/cmd/stringer
+`
+	mf := parseGoMod(splitLines([]byte(src)))
+	assert.Equal(t, "example.com/app", mf.module)
+	assert.Equal(t, 1, mf.moduleLine)
+	assert.Equal(t, []string{"golang.org/x/tools/cmd/stringer"}, mf.tools)
+	assert.Equal(t, []string{
+		"6 github.com/a/lib ok v1",
+		"7 github.com/a/lib ok v2",
+		"8 github.com/b/tool untagged v1",
+		"9 github.com/c/old ok v1",
+		"10 gopkg.in/yaml ok v3",
+		"11 example.com/local ok v1",
+		"14 github.com/d/legacy ok v3",
+		"16 example.com/local local",
+		"19 github.com/a/lib fork",
+	}, summarize(mf))
+	assert.True(t, mf.deps[3].indirect)
+	assert.Len(t, mf.requires, 7)
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	src := `{
+  "name": "web",
+  "dependencies": {
+    "react": "^18.2.0",
+    "lodash": "*",
+    "left-pad": "latest",
+    "shared": "file:../shared",
+    "ui": "workspace:*",
+    "fork": "github:me/fork#fix",
+    "short": "me/short",
+    "old": "npm:lodash@^3.10.1",
+    "zero": "~0.4.2"
+  },
+  "peerDependencies": {"react-dom": "*"},
+  "devDependencies": {"jest": ">=29", "ts": "5.x"}
+}
+`
+	assert.Equal(t, []string{
+		"4 react ok 18",
+		"5 lodash unpinned",
+		"6 left-pad unpinned",
+		"7 shared local",
+		"8 ui ok",
+		"9 fork git",
+		"10 short git",
+		"11 lodash ok 3",
+		"12 zero ok 0.4",
+		"15 jest unpinned",
+		"15 ts ok 5",
+	}, summarize(parsePackageJSON(splitLines([]byte(src)))))
+
+	assert.Empty(t, parsePackageJSON([]string{"{not json"}).deps)
+}
+
+func TestParseRequirements(t *testing.T) {
+	src := `# Runtime
+Django==4.2.1
+requests>=2.0
+numpy
+flask~=2.3 ; python_version >= "3.8"
+urllib3>=1.26,<3
+pytest==7.*
+-r base.txt
+--index-url https://example.com/simple
+-e ./libs/core
+-e git+https://github.com/me/pkg.git@main#egg=pkg
+my_lib @ git+https://github.com/me/my-lib@v1
+celery[redis]==5.3.0 \
+    --hash=sha256:abc
+`
+	assert.Equal(t, []string{
+		"2 django ok 4",
+		"3 requests unpinned",
+		"4 numpy unpinned",
+		"5 flask ok 2",
+		"6 urllib3 ok",
+		"7 pytest unpinned",
+		"10 ./libs/core local",
+		"11 pkg git",
+		"12 my-lib git",
+		"13 celery ok 5",
+	}, summarize(parseRequirements(splitLines([]byte(src)))))
+}
+
+func TestParseCargoToml(t *testing.T) {
+	src := `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+rand = "0.8"
+anything = "*"
+core = { path = "../core" }
+patched = { git = "https://github.com/me/patched", rev = "abc123" }
+log2 = { package = "log", version = "0.4" }
+shared = { workspace = true }
+tokio.version = ">=1"
+
+[target.'cfg(unix)'.dependencies]
+nix = "0.27"
+
+[dev-dependencies.criterion]
+version = "0.5"
+default-features = false
+
+[[bin]]
+name = "tool"
+path = "src/tool.rs"
+
+[patch.crates-io]
+serde = { path = "../serde" }
+`
+	assert.Equal(t, []string{
+		"6 serde ok 1",
+		"7 rand ok 0.8",
+		"8 anything unpinned",
+		"9 core local",
+		"10 patched git",
+		"11 log ok 0.4",
+		"12 shared ok",
+		"13 tokio unpinned",
+		"16 nix ok 0.27",
+		"18 criterion ok 0.5",
+		"27 serde local",
+	}, summarize(parseCargoToml(splitLines([]byte(src)))))
+}
+
+func TestParseGemfile(t *testing.T) {
+	src := `source "https://rubygems.org"
+
+gem "rails", "~> 7.0", ">= 7.0.4"
+gem 'pg'
+gem "nokogiri", ">= 1.10"
+gem "devise", :git => "https://github.com/me/devise.git", branch: "fix"
+gem "engine", path: "engines/engine" # local
+
+group :test do
+  gem "rspec", "3.12.0", require: false
+end
+
+github "me/gems" do
+  gem "forked"
+end
+`
+	mf := parseGemfile(splitLines([]byte(src)))
+	assert.Equal(t, []string{
+		"3 rails ok 7",
+		"4 pg ok",
+		"5 nokogiri unpinned",
+		"6 devise git",
+		"7 engine local",
+		"10 rspec ok 3",
+		"14 forked git",
+	}, summarize(mf))
+	assert.Equal(t, "https://github.com/me/devise.git branch fix", mf.deps[3].spec)
+}
+
+func TestIsManifest(t *testing.T) {
+	for name, want := range map[string]bool{
+		"go.mod":                    true,
+		"web/package.json":          true,
+		"requirements-dev.txt":      true,
+		"Cargo.toml":                true,
+		"Gemfile":                   true,
+		"package-lock.json":         false,
+		"notes.txt":                 false,
+		"pyproject/requirements.md": false,
+	} {
+		assert.Equal(t, want, IsManifest(name), name)
+	}
+}
@@ -0,0 +1,191 @@
+package detector
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+
+	"tech-debt-collector/internal/models"
+)
+
+// ClusterThreshold is the Jaccard similarity of two messages' shingles
+// above which their items belong to one cluster
+const ClusterThreshold = 0.6
+
+// MinHash signatures are split into bands; items sharing any band are
+// compared exactly. 16 bands of 4 rows find pairs at the threshold with
+// high probability.
+const (
+	minHashBands = 16
+	minHashRows  = 4
+	shingleSize  = 3
+)
+
+// minHashSeeds are the fixed hash functions, so clusters are reproducible
+var minHashSeeds = func() [minHashBands * minHashRows][2]uint64 {
+	var seeds [minHashBands * minHashRows][2]uint64
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range seeds {
+		for j := range seeds[i] {
+			x ^= x << 13
+			x ^= x >> 7
+			x ^= x << 17
+			seeds[i][j] = x | 1
+		}
+	}
+	return seeds
+}()
+
+// ClusterItems groups marker items with similar messages across the
+// repository, using shingling and MinHash, and sets each one's ClusterID and
+// its Frequency from the cluster's size. Only items of the same category are
+// grouped. Findings of the analyzers have messages built from a template, so
+// they are left out and keep their Frequency. It returns the clusters,
+// largest first.
+func (d *Detector) ClusterItems(items []models.DebtItem) []models.Cluster {
+	shingles := make([]map[string]bool, len(items))
+	parent := make([]int, len(items))
+	buckets := make(map[string][]int)
+
+	for i := range items {
+		parent[i] = i
+		if !d.isMarker(items[i]) {
+			continue
+		}
+		shingles[i] = shingle(items[i].Message)
+		if len(shingles[i]) == 0 {
+			continue // Nothing to compare, e.g. a bare TODO
+		}
+		sig := minHash(shingles[i])
+		for b := 0; b < minHashBands; b++ {
+			key := append([]byte{byte(b)}, items[i].Category...)
+			for _, h := range sig[b*minHashRows : (b+1)*minHashRows] {
+				key = binary.LittleEndian.AppendUint64(key, h)
+			}
+			buckets[string(key)] = append(buckets[string(key)], i)
+		}
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, members := range buckets {
+		for x, i := range members {
+			for _, j := range members[x+1:] {
+				if find(i) != find(j) && jaccard(shingles[i], shingles[j]) >= ClusterThreshold {
+					parent[find(j)] = find(i)
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range items {
+		if !d.isMarker(items[i]) {
+			continue
+		}
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+
+	clusters := make([]models.Cluster, 0, len(groups))
+	for _, members := range groups {
+		c := newCluster(items, members)
+		for _, i := range members {
+			items[i].ClusterID = c.ID
+			items[i].Frequency = d.frequencyToScore(len(members))
+		}
+		clusters = append(clusters, c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Size != clusters[j].Size {
+			return clusters[i].Size > clusters[j].Size
+		}
+		return clusters[i].ID < clusters[j].ID
+	})
+	return clusters
+}
+
+// isMarker reports whether item was found by a marker rule, and so has a
+// free-text message
+func (d *Detector) isMarker(item models.DebtItem) bool {
+	_, ok := d.rules[item.Type]
+	return ok
+}
+
+// newCluster describes the items at members. Its ID derives from the
+// smallest member ID, so it survives unrelated items joining or leaving.
+func newCluster(items []models.DebtItem, members []int) models.Cluster {
+	first := members[0]
+	files := make(map[string]bool)
+	for _, i := range members {
+		if items[i].ID < items[first].ID || items[i].ID == items[first].ID && i < first {
+			first = i
+		}
+		files[items[i].FilePath] = true
+	}
+	return models.Cluster{
+		ID:       fingerprint("cluster\x00" + items[first].ID + "\x00" + items[first].Message),
+		Size:     len(members),
+		Files:    len(files),
+		Type:     items[first].Type,
+		Category: items[first].Category,
+		Message:  items[first].Message,
+	}
+}
+
+// shingle returns the character shingles of a message's words, lowercased
+// and with punctuation dropped, so "Handle timeout!" and "handle timeouts"
+// share most of theirs
+func shingle(message string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	text := strings.Join(words, " ")
+	if len(text) < shingleSize {
+		return nil
+	}
+
+	set := make(map[string]bool)
+	for i := 0; i+shingleSize <= len(text); i++ {
+		set[text[i:i+shingleSize]] = true
+	}
+	return set
+}
+
+// minHash is the MinHash signature of a shingle set
+func minHash(set map[string]bool) []uint64 {
+	sig := make([]uint64, len(minHashSeeds))
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for s := range set {
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		x := h.Sum64()
+		for i, seed := range minHashSeeds {
+			v := (x ^ seed[1]) * seed[0]
+			if v ^= v >> 29; v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// jaccard is the size of the intersection of a and b over their union
+func jaccard(a, b map[string]bool) float64 {
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
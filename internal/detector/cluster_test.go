@@ -0,0 +1,108 @@
+package detector
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/config"
+	"tech-debt-collector/internal/models"
+)
+
+func TestClusterItems(t *testing.T) {
+	var items []models.DebtItem
+	for i := 0; i < 14; i++ {
+		msg := "handle timeout"
+		switch i % 3 {
+		case 1:
+			msg = "Handle timeouts!"
+		case 2:
+			msg = "handle the timeout"
+		}
+		items = append(items, models.DebtItem{
+			ID:       fmt.Sprintf("t%02d", i),
+			FilePath: fmt.Sprintf("pkg/f%d.go", i%7),
+			Type:     "TODO",
+			Category: models.CategoryComment,
+			Message:  msg,
+		})
+	}
+	items = append(items,
+		models.DebtItem{ID: "a", FilePath: "a.go", Type: "FIXME", Category: models.CategoryComment, Message: "remove global state"},
+		models.DebtItem{ID: "b", FilePath: "b.go", Type: "TODO", Category: models.CategoryComment, Message: "remove the global state"},
+		models.DebtItem{ID: "c", FilePath: "c.go", Type: "TODO", Category: models.CategoryComment, Message: "support IPv6"},
+		models.DebtItem{ID: "d", FilePath: "d.go", Type: "TODO", Category: models.CategoryComment},
+		models.DebtItem{ID: "e", FilePath: "e.go", Type: "LONG_FUNCTION", Category: models.CategoryStructure, Message: "handle timeout"},
+	)
+
+	clusters := NewDetector().ClusterItems(items)
+
+	if !assert.Len(t, clusters, 4) {
+		return
+	}
+	assert.Equal(t, 14, clusters[0].Size)
+	assert.Equal(t, 7, clusters[0].Files)
+	assert.Equal(t, "handle timeout", clusters[0].Message)
+	assert.Equal(t, 2, clusters[1].Size)
+
+	for _, item := range items[:14] {
+		assert.Equal(t, clusters[0].ID, item.ClusterID)
+		assert.Equal(t, 5, item.Frequency)
+	}
+	assert.Equal(t, items[14].ClusterID, items[15].ClusterID)
+	assert.Equal(t, 2, items[14].Frequency)
+	assert.NotEqual(t, items[16].ClusterID, items[14].ClusterID)
+	assert.Equal(t, 1, items[16].Frequency)
+	assert.NotEmpty(t, items[17].ClusterID)
+	assert.Empty(t, items[18].ClusterID, "findings of the analyzers aren't clustered")
+	assert.Zero(t, items[18].Frequency)
+}
+
+func TestClusterItemsOnlyGroupsMarkers(t *testing.T) {
+	var items []models.DebtItem
+	for i := 0; i < 5; i++ {
+		items = append(items,
+			models.DebtItem{ID: fmt.Sprintf("f%d", i), FilePath: fmt.Sprintf("p%d/p.go", i), Type: "LONG_FUNCTION",
+				Category: models.CategoryStructure, Message: fmt.Sprintf("function p%d.Run is 120 lines long (max 80)", i), Frequency: 1},
+			models.DebtItem{ID: fmt.Sprintf("l%d", i), FilePath: fmt.Sprintf("p%d/p.go", i), Type: "LINT_SUPPRESSION",
+				Category: models.CategoryLint, Message: "nolint:errcheck suppresses a linter warning"},
+		)
+	}
+	assert.Empty(t, NewDetector().ClusterItems(items))
+	for _, item := range items {
+		assert.Empty(t, item.ClusterID)
+	}
+	assert.Equal(t, 1, items[0].Frequency)
+
+	// A configured marker is clustered, whatever its category
+	d, err := NewDetectorWithConfig(&config.Config{Markers: []config.Marker{{Name: "OPTIMIZE", Category: "performance"}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	items = []models.DebtItem{
+		{ID: "o1", FilePath: "a.go", Type: "OPTIMIZE", Category: "performance", Message: "cache the lookup"},
+		{ID: "o2", FilePath: "b.go", Type: "OPTIMIZE", Category: "performance", Message: "cache the lookups"},
+	}
+	clusters := d.ClusterItems(items)
+	if assert.Len(t, clusters, 1) {
+		assert.Equal(t, 2, clusters[0].Size)
+	}
+}
+
+func TestClusterIDsAreStable(t *testing.T) {
+	items := []models.DebtItem{
+		{ID: "x1", FilePath: "a.go", Type: "TODO", Category: models.CategoryComment, Message: "retry on failure"},
+		{ID: "x2", FilePath: "b.go", Type: "TODO", Category: models.CategoryComment, Message: "retry on failures"},
+	}
+	d := NewDetector()
+	d.ClusterItems(items)
+	id := items[0].ClusterID
+
+	// A new similar item elsewhere joins the cluster without renaming it
+	items = append([]models.DebtItem{{ID: "x3", FilePath: "c.go", Type: "TODO", Category: models.CategoryComment, Message: "Retry on failure."}}, items...)
+	d.ClusterItems(items)
+	for _, item := range items {
+		assert.Equal(t, id, item.ClusterID)
+	}
+}
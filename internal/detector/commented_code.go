@@ -0,0 +1,236 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"tech-debt-collector/internal/lang"
+	"tech-debt-collector/internal/models"
+)
+
+// TypeCommentedCode marks a block of commented-out code
+const TypeCommentedCode = "COMMENTED_CODE"
+
+// A comment line is code-like when its features score at least
+// codeLineScore. A run of such lines is reported when it has at least
+// minCodeLines of them and they make up codeRatio of its non-blank lines.
+const (
+	codeLineScore = 2
+	minCodeLines  = 2
+	codeRatio     = 0.6
+)
+
+// commentedCodeSeverities scale with the reported block's size: the first
+// entry whose line count is reached applies
+var commentedCodeSeverities = []struct{ lines, severity int }{
+	{50, 5},
+	{25, 4},
+	{10, 3},
+	{0, 2},
+}
+
+// codeKeywords are the words that start statements and declarations in
+// each language. Commented-out code is only looked for in these languages.
+var codeKeywords = map[string][]string{
+	"go":         {"if", "for", "func", "return", "var", "const", "type", "import", "package", "defer", "go", "switch", "case", "select", "else", "break", "continue"},
+	"python":     {"def", "class", "if", "elif", "else", "for", "while", "return", "import", "from", "try", "except", "finally", "with", "raise", "yield", "pass", "print", "assert", "async", "await"},
+	"javascript": {"function", "const", "let", "var", "if", "for", "while", "return", "import", "export", "class", "new", "await", "throw", "try", "catch", "switch", "case", "else"},
+	"typescript": {"function", "const", "let", "var", "if", "for", "while", "return", "import", "export", "class", "new", "await", "throw", "try", "catch", "switch", "case", "else", "interface", "type", "enum"},
+	"java":       {"public", "private", "protected", "static", "final", "class", "void", "return", "if", "for", "while", "new", "import", "package", "try", "catch", "throw", "else", "switch", "case", "int", "String"},
+	"kotlin":     {"fun", "val", "var", "class", "if", "for", "while", "return", "when", "import", "package", "object", "else", "try", "catch", "throw"},
+	"scala":      {"def", "val", "var", "if", "for", "while", "return", "import", "package", "object", "class", "match", "case", "else"},
+	"groovy":     {"def", "if", "for", "while", "return", "import", "class", "new", "else", "try", "catch"},
+	"c":          {"int", "char", "void", "return", "if", "for", "while", "struct", "static", "const", "#include", "#define", "#if", "#ifdef", "#endif", "else", "switch", "case", "typedef", "unsigned"},
+	"cpp":        {"int", "char", "void", "auto", "return", "if", "for", "while", "struct", "class", "namespace", "template", "static", "const", "#include", "#define", "#if", "#ifdef", "#endif", "else", "switch", "case", "std"},
+	"csharp":     {"public", "private", "protected", "static", "class", "void", "return", "if", "for", "foreach", "while", "new", "using", "namespace", "var", "else", "try", "catch", "throw"},
+	"swift":      {"func", "let", "var", "if", "for", "while", "return", "import", "class", "struct", "guard", "else", "switch", "case"},
+	"rust":       {"fn", "let", "if", "for", "while", "match", "return", "use", "struct", "impl", "pub", "mod", "else", "loop"},
+	"ruby":       {"def", "end", "if", "elsif", "else", "unless", "while", "require", "class", "module", "return", "puts"},
+	"php":        {"function", "if", "foreach", "for", "while", "return", "echo", "class", "public", "private", "use", "namespace", "else", "new"},
+	"shell":      {"if", "then", "fi", "for", "do", "done", "while", "case", "esac", "echo", "export", "local", "function", "return", "else", "elif"},
+}
+
+var (
+	assignmentRe    = regexp.MustCompile(`^[\w.$\[\]"'*&]+(?:\s*,\s*[\w.$]+)*\s*(?::=|[-+*/|&]?=)\s*[^=\s]`)
+	callRe          = regexp.MustCompile(`^[\w.$:]+(?:<[^>]*>)?\(.*\)[;,]?$`)
+	operatorRe      = regexp.MustCompile(`==|!=|&&|\|\||->|=>|::|\+\+|--$`)
+	closerRe        = regexp.MustCompile(`^[})\]]+[;,)]*$`)
+	proseRe         = regexp.MustCompile(`(?:^|\s)[a-zA-Z]+\s+[a-z]+\s+[a-z]+\s+[a-z]+(?:\s|$)`)
+	toolDirectiveRe = regexp.MustCompile(`^(?:[a-z]+:[a-z]|\+build\b)`)
+)
+
+// commentedCode reports runs of comment lines that look like code rather
+// than prose
+type commentedCode struct{}
+
+func (commentedCode) Analyze(src *source) []models.DebtItem {
+	keywords, ok := codeKeywords[src.Language]
+	if !ok {
+		return nil
+	}
+	l, _ := lang.Lookup(src.Language)
+
+	var (
+		items     []models.DebtItem
+		run       codeRun
+		lastBlock = -1
+	)
+	flush := func() {
+		if item, ok := run.item(); ok {
+			items = append(items, item)
+		}
+		run = codeRun{}
+	}
+
+	for _, cl := range src.Comments {
+		if cl.Block != lastBlock {
+			flush()
+			lastBlock = cl.Block
+		}
+		if !wholeLineComment(l, src.Lines[cl.Line-1], cl.Text) {
+			flush()
+			continue
+		}
+
+		text := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(cl.Text), "*"))
+		switch {
+		case text == "":
+			// Blank comment lines neither extend nor break a run
+		case src.Language == "go" && isGoDocExample(cl.Text) && run.code == 0:
+			// An example in a doc comment, unless it continues a run of code
+		case isToolDirective(text):
+			flush()
+		case codeScore(text, keywords) >= codeLineScore:
+			run.add(cl.Line, true)
+		default:
+			if run.add(cl.Line, false) {
+				flush()
+			}
+		}
+	}
+	flush()
+	return items
+}
+
+// codeRun is a run of comment lines that is mostly code
+type codeRun struct {
+	start, end  int // First and last code-like line
+	code, prose int
+	gap         int // Prose lines since the last code-like line
+}
+
+// add records one non-blank comment line and reports whether the run is
+// broken, by two prose lines in a row
+func (r *codeRun) add(line int, code bool) bool {
+	if code {
+		if r.code == 0 {
+			r.start = line
+		}
+		r.end = line
+		r.code++
+		r.prose += r.gap
+		r.gap = 0
+		return false
+	}
+	if r.code == 0 {
+		return false
+	}
+	r.gap++
+	return r.gap > 1
+}
+
+func (r *codeRun) item() (models.DebtItem, bool) {
+	if r.code < minCodeLines || float64(r.code) < codeRatio*float64(r.code+r.prose) {
+		return models.DebtItem{}, false
+	}
+
+	lines := r.end - r.start + 1
+	severity := 2
+	for _, s := range commentedCodeSeverities {
+		if lines >= s.lines {
+			severity = s.severity
+			break
+		}
+	}
+	return models.DebtItem{
+		LineNumber: r.start,
+		EndLine:    r.end,
+		Type:       TypeCommentedCode,
+		Category:   models.CategoryComment,
+		Message:    fmt.Sprintf("%d lines of commented-out code", lines),
+		Severity:   severity,
+	}, true
+}
+
+// codeScore rates how code-like one line of comment text is. Statement
+// terminators, braces and assignments count most; keywords, calls and
+// operators less; sentences count against.
+func codeScore(text string, keywords []string) int {
+	score := 0
+	switch {
+	case strings.HasSuffix(text, ";"), strings.HasSuffix(text, "{"), closerRe.MatchString(text):
+		score += 2
+	}
+	if assignmentRe.MatchString(text) {
+		score += 2
+	}
+	if callRe.MatchString(text) {
+		score += 2
+	}
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '(' || r == ':' || r == '<'
+	})
+	if len(words) > 0 {
+		for _, kw := range keywords {
+			if words[0] == kw {
+				score++
+				if len(words) <= 3 || strings.HasSuffix(text, ":") {
+					score++ // A short statement such as "return nil", or a Python block
+				}
+				break
+			}
+		}
+	}
+	if operatorRe.MatchString(text) {
+		score++
+	}
+
+	if (strings.HasSuffix(text, ".") || strings.HasSuffix(text, "?") || strings.HasSuffix(text, "!")) && !strings.HasSuffix(text, "...") {
+		score -= 2
+	}
+	if proseRe.MatchString(text) {
+		score--
+	}
+	return score
+}
+
+// wholeLineComment reports whether a line holds nothing but the comment:
+// commented-out code takes whole lines, while a trailing comment annotates
+// the code before it. Python docstrings don't count.
+func wholeLineComment(l lang.Language, line, text string) bool {
+	if l.DocStrings {
+		return isCommentLine(l, line)
+	}
+	rest := strings.Replace(line, text, "", 1)
+	for _, tok := range l.LineComments {
+		rest = strings.Replace(rest, tok, "", 1)
+	}
+	for _, pair := range l.BlockComments {
+		rest = strings.Replace(rest, pair[0], "", 1)
+		rest = strings.Replace(rest, pair[1], "", 1)
+	}
+	return strings.Trim(rest, " \t*") == ""
+}
+
+// isGoDocExample recognises the indented code blocks of Go doc comments
+func isGoDocExample(text string) bool {
+	return strings.HasPrefix(text, "\t") || strings.HasPrefix(text, "   ")
+}
+
+// isToolDirective recognises comments read by tools, such as //go:generate
+// and // +build
+func isToolDirective(text string) bool {
+	return toolDirectiveRe.MatchString(text)
+}
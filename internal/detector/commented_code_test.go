@@ -0,0 +1,105 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func commentedCodeItems(t *testing.T, path, src string) []string {
+	items, err := NewDetector().DetectInReader(path, strings.NewReader(src), 3)
+	assert.NoError(t, err)
+
+	var got []string
+	for _, item := range items {
+		if item.Type == TypeCommentedCode {
+			got = append(got, fmt.Sprintf("%d-%d %d", item.LineNumber, item.EndLine, item.Severity))
+		}
+	}
+	return got
+}
+
+func TestCommentedCode(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		src  string
+		want []string
+	}{
+		{
+			"go statements",
+			"a.go",
+			"package a\n\nfunc f() {\n\t// x, err := load()\n\t// if err != nil {\n\t//     return err\n\t// }\n\tg()\n}\n",
+			[]string{"4-7 2"},
+		},
+		{
+			"block comment",
+			"a.js",
+			"/*\nconst total = items.reduce(sum, 0);\nrender(total);\n*/\n",
+			[]string{"2-3 2"},
+		},
+		{
+			"python",
+			"a.py",
+			"# for item in items:\n#     process(item)\nrun()\n",
+			[]string{"1-2 2"},
+		},
+		{
+			"one prose line inside code",
+			"A.java",
+			"// int count = 0;\n// the old loop\n// count++;\n",
+			[]string{"1-3 2"},
+		},
+		{
+			"prose",
+			"a.go",
+			"// Load reads the config. It returns an error if the file is\n// missing, so callers (see main) should check it.\n// If err is nil, use it.\n",
+			nil,
+		},
+		{
+			"go doc example",
+			"a.go",
+			"// Foo does things, e.g.\n//\n//\tx := Foo()\n//\tx.Bar()\nfunc Foo() {}\n",
+			nil,
+		},
+		{
+			"trailing comments",
+			"a.c",
+			"int x = 1; // y = 2;\nint z = 3; // w = 4;\n",
+			nil,
+		},
+		{
+			"tool directives",
+			"a.go",
+			"//go:build linux\n//go:generate stringer -type=Kind\npackage a\n",
+			nil,
+		},
+		{
+			"single line",
+			"a.go",
+			"// fmt.Println(x)\n",
+			nil,
+		},
+		{
+			"unsupported language",
+			"a.yaml",
+			"# key: value\n# other: 1\n",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, commentedCodeItems(t, tt.path, tt.src))
+		})
+	}
+}
+
+func TestCommentedCodeSeverityScalesWithSize(t *testing.T) {
+	for _, tt := range []struct{ lines, severity int }{{9, 2}, {10, 3}, {25, 4}, {60, 5}} {
+		src := strings.Repeat("// total += step(i);\n", tt.lines)
+		assert.Equal(t, []string{fmt.Sprintf("1-%d %d", tt.lines, tt.severity)}, commentedCodeItems(t, "a.c", src))
+	}
+}
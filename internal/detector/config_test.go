@@ -0,0 +1,49 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+
+	"tech-debt-collector/internal/config"
+	"tech-debt-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectorWithConfig(t *testing.T) {
+	d, err := NewDetectorWithConfig(&config.Config{Markers: []config.Marker{
+		{Name: "OPTIMIZE", Severity: 1, Category: "performance"},
+		{Name: "DEBT", Pattern: `@debt\b`, Languages: []string{"java"}},
+		{Name: "hack", Severity: 1},
+		{Name: "XXX", Disabled: true},
+	}})
+	assert.NoError(t, err)
+
+	src := "// OPTIMIZE: cache this\n// @debt: old client\n// HACK: skip\n// XXX: gone\n// TODO: still here\n"
+	items, err := d.DetectInReader("a.go", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+
+	var types []string
+	for _, item := range items {
+		types = append(types, item.Type)
+	}
+	assert.Equal(t, []string{"OPTIMIZE", "HACK", "TODO"}, types, "DEBT is limited to Java and XXX is disabled")
+	assert.Equal(t, "performance", items[0].Category)
+	assert.Equal(t, "cache this", items[0].Message)
+	assert.Equal(t, 1, items[1].Severity, "overrides keep the built-in name")
+	assert.Equal(t, models.CategoryComment, items[2].Category)
+
+	items, err = d.DetectInReader("A.java", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "DEBT", items[1].Type)
+	assert.Equal(t, "old client", items[1].Message)
+	assert.Equal(t, 2, items[1].Severity)
+}
+
+func TestDetectorWithConfigErrors(t *testing.T) {
+	_, err := NewDetectorWithConfig(&config.Config{Markers: []config.Marker{{Name: "BAD", Pattern: "a("}}})
+	assert.ErrorContains(t, err, `invalid pattern "a("`)
+
+	_, err = NewDetectorWithConfig(&config.Config{Markers: []config.Marker{{Name: "NOPE", Disabled: true}}})
+	assert.ErrorContains(t, err, "cannot disable unknown marker")
+}
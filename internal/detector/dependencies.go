@@ -0,0 +1,22 @@
+package detector
+
+import (
+	"tech-debt-collector/internal/deps"
+	"tech-debt-collector/internal/models"
+)
+
+// dependencies reports debt in dependency manifests, and checks each
+// go.mod against the imports of the Go files scanned with it
+type dependencies struct {
+	manifests *deps.Manifests
+}
+
+func (dep dependencies) Collect(src *source) bool {
+	return dep.manifests.Add(src.Path, src.Data)
+}
+
+func (dep dependencies) Finish() []models.DebtItem {
+	items := dep.manifests.Findings()
+	dep.manifests.Reset()
+	return items
+}
@@ -1,84 +1,458 @@
 package detector
 
 import (
-	"bufio"
-	"crypto/md5"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"tech-debt-collector/internal/clones"
+	"tech-debt-collector/internal/config"
+	"tech-debt-collector/internal/deps"
+	"tech-debt-collector/internal/goast"
+	"tech-debt-collector/internal/lang"
 	"tech-debt-collector/internal/models"
+	"tech-debt-collector/internal/secrets"
 )
 
 // Detector detects technical debt items in source files
 type Detector struct {
-	patterns map[string]*regexp.Regexp
-	typeMap  map[string]int // Type to default severity
+	// Root, when set, is stripped from file paths before fingerprinting
+	// item IDs, so IDs don't depend on where the repository is checked out
+	Root string
+	// Now is the clock that decides whether a snooze has expired
+	Now func() time.Time
+	// Partial means only some of the repository's files are detected, as
+	// with a change set, so analyses that need every file hold back
+	// findings about what is absent, such as unused dependencies
+	Partial bool
+
+	rules         map[string]*rule
+	types         []string // Sorted types, so items come out in a stable order
+	analyzers     []analyzer
+	repoAnalyzers []repoAnalyzer
+	secrets       *secrets.Scanner // Masks secrets quoted in any item's message
+	docs          *goast.Docs
+	manifests     *deps.Manifests
+
+	mu    sync.Mutex
+	files map[string]*fileState // Files collected by a repoAnalyzer since Begin; nil outside a session
+}
+
+// source is one file's content as seen by the detector
+type source struct {
+	Path     string
+	Rel      string // Path relative to the scan root, for rules about paths
+	Language string
+	Data     []byte
+	Lines    []string           // Raw lines
+	Comments []lang.CommentLine // Comment text, or every line if the language is unknown
+	Secrets  []string           // Values of the secrets found in the file, set by the secrets analyzer
+}
+
+// analyzer finds one kind of debt in a file, alongside the comment markers.
+// Items need only their lines, type, category, message and severity; the
+// detector fills in the rest.
+type analyzer interface {
+	Analyze(src *source) []models.DebtItem
+}
+
+// repoAnalyzer finds debt that spans files, such as uses of a symbol
+// declared in another package. Collect sees every file, possibly from
+// several goroutines at once, and reports whether it kept anything from it.
+// Finish runs once all files are seen; its items also need their FilePath.
+type repoAnalyzer interface {
+	Collect(src *source) bool
+	Finish() []models.DebtItem
+}
+
+// fileState is what the detector keeps of a file to finish its items
+type fileState struct {
+	path, language string
+	importance     int
+	lines          []string
+	directives     []directive
+	blocks         map[int]int // Comment block of each comment line, if there are directives
+	secrets        []string    // Masked in every item of the file
+}
+
+// rule is a compiled debt marker
+type rule struct {
+	pattern   *regexp.Regexp
+	severity  int             // Default severity
+	category  string          // Reported as DebtItem.Category
+	languages map[string]bool // Nil applies to every language
+}
+
+// BuiltinMarkers are the markers detected without any configuration
+var BuiltinMarkers = []config.Marker{
+	{Name: "TODO", Severity: 2},
+	{Name: "FIXME", Severity: 3},
+	{Name: "HACK", Severity: 4},
+	{Name: "DEPRECATED", Severity: 3},
+	{Name: "XXX", Severity: 4},
 }
 
-// NewDetector creates a new tech debt detector
+// NewDetector creates a new tech debt detector for the built-in markers
 func NewDetector() *Detector {
+	d, err := NewDetectorWithConfig(&config.Config{})
+	if err != nil {
+		panic(err) // The built-in markers always compile
+	}
+	return d
+}
+
+// NewDetectorWithConfig creates a detector for the built-in markers merged
+// with those of cfg. A config marker named like a built-in one overrides the
+// fields it sets, or removes it when disabled.
+func NewDetectorWithConfig(cfg *config.Config) (*Detector, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	markers := make(map[string]config.Marker)
+	for _, m := range BuiltinMarkers {
+		markers[m.Name] = m
+	}
+	for _, m := range cfg.Markers {
+		name := strings.TrimSpace(m.Name)
+		key := strings.ToUpper(name)
+		base, builtin := markers[key]
+		switch {
+		case m.Disabled && !builtin:
+			return nil, fmt.Errorf("marker %q: cannot disable unknown marker", name)
+		case m.Disabled:
+			delete(markers, key)
+		case builtin:
+			markers[key] = merge(base, m)
+		default:
+			m.Name = name
+			if m.Severity == 0 {
+				m.Severity = 2
+			}
+			markers[name] = m
+		}
+	}
+
+	goRules, err := goast.NewAnalyzer(cfg.Go)
+	if err != nil {
+		return nil, err
+	}
+
+	secretScanner, err := secrets.NewScanner(cfg.Secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := goast.NewDocs(goRules)
+	manifests := deps.NewManifests()
 	d := &Detector{
-		patterns: make(map[string]*regexp.Regexp),
-		typeMap: map[string]int{
-			"TODO":       2,
-			"FIXME":      3,
-			"HACK":       4,
-			"DEPRECATED": 3,
-			"XXX":        4,
-		},
+		Now:           time.Now,
+		rules:         make(map[string]*rule),
+		analyzers:     []analyzer{lintSuppressions{}, skippedTests{}, commentedCode{}, goStructure{goRules}, infrastructure{}},
+		repoAnalyzers: []repoAnalyzer{goDeprecations{goast.NewDeprecations()}, dependencies{manifests}, goDocs{docs}},
+		secrets:       secretScanner,
+		docs:          docs,
+		manifests:     manifests,
+	}
+	if !cfg.Secrets.Disabled {
+		d.analyzers = append(d.analyzers, hardcodedSecrets{secretScanner})
 	}
+	if !cfg.Duplication.Disabled {
+		d.repoAnalyzers = append(d.repoAnalyzers, duplication{clones.NewIndex(cfg.Duplication.MinTokens)})
+	}
+	for typeStr, m := range markers {
+		r, err := compileRule(m)
+		if err != nil {
+			return nil, fmt.Errorf("marker %q: %w", typeStr, err)
+		}
+		d.rules[typeStr] = r
+		d.types = append(d.types, typeStr)
+	}
+	sort.Strings(d.types)
+
+	return d, nil
+}
+
+// merge applies the fields set in override to a built-in marker
+func merge(base, override config.Marker) config.Marker {
+	if override.Pattern != "" {
+		base.Pattern = override.Pattern
+	}
+	if override.Severity != 0 {
+		base.Severity = override.Severity
+	}
+	if override.Category != "" {
+		base.Category = override.Category
+	}
+	if len(override.Languages) > 0 {
+		base.Languages = override.Languages
+	}
+	return base
+}
 
-	// Compile regex patterns
-	for typeStr := range d.typeMap {
-		// Match: TODO, TODO:, TODO: message, # TODO: message, etc.
-		pattern := regexp.MustCompile(fmt.Sprintf(`(?i)(%s)[\s:]*(.*)$`, typeStr))
-		d.patterns[typeStr] = pattern
+// compileRule builds the regex for a marker. The message is whatever follows
+// the marker, and is always the last submatch; the separator before it is
+// the one before that.
+func compileRule(m config.Marker) (*rule, error) {
+	pattern := m.Pattern
+	if pattern == "" {
+		pattern = wordPattern(m.Name)
+	}
+	// Match TODO, TODO:, TODO: message, but (by default) not todoList
+	re, err := regexp.Compile(fmt.Sprintf(`(?i)(?:%s)([\s:]*)(.*)$`, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", m.Pattern, err)
 	}
 
-	return d
+	r := &rule{pattern: re, severity: m.Severity, category: m.Category}
+	if r.category == "" {
+		r.category = models.CategoryComment
+	}
+	if len(m.Languages) > 0 {
+		r.languages = make(map[string]bool)
+		for _, id := range m.Languages {
+			r.languages[id] = true
+		}
+	}
+	return r, nil
+}
+
+// wordPattern matches name as a whole word. Word boundaries are only
+// required next to word characters, so "@debt" works too.
+func wordPattern(name string) string {
+	pattern := regexp.QuoteMeta(name)
+	if isWordByte(name[0]) {
+		pattern = `\b` + pattern
+	}
+	if isWordByte(name[len(name)-1]) {
+		pattern += `\b`
+	}
+	return pattern
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
 }
 
 // DetectInFile scans a file for technical debt items
 func (d *Detector) DetectInFile(filePath string, fileImportance int) ([]models.DebtItem, error) {
-	var items []models.DebtItem
-
 	file, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	lineNumber := 0
+	return d.DetectInReader(filePath, file, fileImportance)
+}
+
+// DetectInFS scans the named file of fsys for technical debt items
+func (d *Detector) DetectInFS(fsys fs.FS, name string, fileImportance int) ([]models.DebtItem, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return d.DetectInReader(name, file, fileImportance)
+}
+
+// DetectInReader scans content read from r, such as an unsaved editor buffer
+// or an uploaded file, reporting items against filePath
+func (d *Detector) DetectInReader(filePath string, r io.Reader, fileImportance int) ([]models.DebtItem, error) {
+	return d.DetectInReaderAs(filePath, "", r, fileImportance)
+}
+
+// DetectInReaderAs is DetectInReader for content whose language is already
+// known, e.g. from the scanner. An empty language is detected from the path
+// and content. The language's comment syntax decides which text is searched.
+func (d *Detector) DetectInReaderAs(filePath, language string, r io.Reader, fileImportance int) ([]models.DebtItem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if language == "" {
+		language = lang.Detect(filePath, lang.Head(data))
+	}
+
+	raw := splitLines(data)
+	src := &source{Path: filePath, Rel: d.fingerprintPath(filePath), Language: language, Data: data, Lines: raw, Comments: d.searchableLines(language, raw)}
+	f := &fileState{path: filePath, language: language, importance: fileImportance, lines: raw}
+	f.directives = extractDirectives(src.Comments)
+	if len(f.directives) > 0 {
+		f.blocks = make(map[int]int, len(src.Comments))
+		for _, cl := range src.Comments {
+			f.blocks[cl.Line] = cl.Block
+		}
+	}
+
+	items := d.detectMarkers(src)
+	for _, a := range d.analyzers {
+		items = append(items, a.Analyze(src)...)
+	}
+	items = mergeReportedCredentials(items)
+	f.secrets = src.Secrets
+	d.finish(f, items)
+
+	if d.reserve(f) {
+		kept := false
+		for _, a := range d.repoAnalyzers {
+			kept = a.Collect(src) || kept
+		}
+		if !kept {
+			d.mu.Lock()
+			delete(d.files, filePath)
+			d.mu.Unlock()
+		}
+	}
+
+	return items, nil
+}
+
+// reserve claims f's path for the repoAnalyzers. Outside a session, or when
+// the path was already collected in this one, it reports false, so a rescan
+// can't be compared with itself.
+func (d *Detector) reserve(f *fileState) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.files == nil {
+		return false
+	}
+	if _, ok := d.files[f.path]; ok {
+		return false
+	}
+	d.files[f.path] = f
+	return true
+}
+
+// Begin starts a session of analyses that need every file, such as finding
+// uses of deprecated Go symbols or code copied across files. Files detected
+// until Finish are collected for them; outside a session each file is
+// analyzed on its own and nothing is kept. Begin does nothing if a session
+// is already open.
+func (d *Detector) Begin() {
+	d.mu.Lock()
+	if d.files == nil {
+		d.files = make(map[string]*fileState)
+	}
+	d.mu.Unlock()
+}
+
+// finish orders the items found in one file, fills in their common fields,
+// applies the file's directives, masks the file's secrets in any text the
+// items quote and assigns IDs
+func (d *Detector) finish(f *fileState, items []models.DebtItem) {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].LineNumber < items[j].LineNumber })
+
+	now := time.Now()
+	for i := range items {
+		items[i].FilePath = f.path
+		items[i].Language = f.language
+		items[i].FileImportance = f.importance
+		items[i].DetectedAt = now
+	}
+
+	if len(f.directives) > 0 {
+		applyDirectives(items, f.directives, f.blocks, d.Now())
+	}
+	if d.secrets != nil {
+		for i := range items {
+			items[i].Message = d.secrets.Mask(items[i].Message, f.secrets)
+			items[i].SkipReason = d.secrets.Mask(items[i].SkipReason, f.secrets)
+			if sup := items[i].Suppression; sup != nil {
+				sup.Reason = d.secrets.Mask(sup.Reason, f.secrets)
+			}
+		}
+	}
+	d.assignIDs(f.path, f.lines, items)
+}
+
+// Finish runs the analyses that need every file over the files detected
+// since Begin, and ends the session. Items come out ordered by path and line.
+// The detector then forgets those files.
+func (d *Detector) Finish() []models.DebtItem {
+	d.mu.Lock()
+	files := d.files
+	d.files = nil
+	d.mu.Unlock()
+
+	d.manifests.Partial = d.Partial
+	byPath := make(map[string][]models.DebtItem)
+	for _, a := range d.repoAnalyzers {
+		for _, item := range a.Finish() {
+			if _, ok := files[item.FilePath]; ok {
+				byPath[item.FilePath] = append(byPath[item.FilePath], item)
+			}
+		}
+	}
 
-	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var items []models.DebtItem
+	for _, path := range paths {
+		found := byPath[path]
+		d.finish(files[path], found)
+		items = append(items, found...)
+	}
+	return items
+}
+
+// DocCoverage returns the documentation coverage of each Go package seen by
+// the last Finish, least documented first. Package directories are relative
+// to Root, like the paths item IDs are made from.
+func (d *Detector) DocCoverage() []models.PackageDocs {
+	coverage := d.docs.Coverage()
+	out := make([]models.PackageDocs, len(coverage))
+	for i, pkg := range coverage {
+		pkg.Package = d.fingerprintPath(pkg.Package)
+		out[i] = pkg
+	}
+	return out
+}
 
+// detectMarkers matches the marker rules against the file's comments
+func (d *Detector) detectMarkers(src *source) []models.DebtItem {
+	var items []models.DebtItem
+	lines := src.Comments
+	for i, cl := range lines {
 		// Check against each pattern
-		for typeStr, pattern := range d.patterns {
-			matches := pattern.FindStringSubmatch(line)
+		for _, typeStr := range d.types {
+			r := d.rules[typeStr]
+			if r.languages != nil && !r.languages[src.Language] {
+				continue
+			}
+			matches := r.pattern.FindStringSubmatch(cl.Text)
 			if len(matches) > 0 {
-				message := ""
-				if len(matches) > 2 {
-					message = strings.TrimSpace(matches[2])
+				message := strings.TrimSpace(matches[len(matches)-1])
+				continuation, endLine := d.continuation(lines[i:])
+				if continuation != "" {
+					message = strings.TrimSpace(message + " " + continuation)
 				}
+				meta, message := parseMetadata(message, matches[len(matches)-2] == "")
 
 				// Detect severity from message context
 				severity := d.detectSeverity(typeStr, message)
 
 				item := models.DebtItem{
-					ID:             d.generateID(filePath, lineNumber),
-					FilePath:       filePath,
-					LineNumber:     lineNumber,
-					Type:           typeStr,
-					Message:        message,
-					Severity:       severity,
-					FileImportance: fileImportance,
-					DetectedAt:     time.Now(),
+					LineNumber: cl.Line,
+					EndLine:    endLine,
+					Type:       typeStr,
+					Category:   r.category,
+					Message:    message,
+					Owner:      meta.Owner,
+					IssueRefs:  meta.IssueRefs,
+					DueDate:    meta.DueDate,
+					DueVersion: meta.DueVersion,
+					Severity:   severity,
 				}
 
 				items = append(items, item)
@@ -86,16 +460,72 @@ func (d *Detector) DetectInFile(filePath string, fileImportance int) ([]models.D
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	return items
+}
+
+// continuation joins the comment lines that follow lines[0] in the same
+// comment block, stopping at a blank line or another marker. It returns the
+// joined text and the last line used.
+func (d *Detector) continuation(lines []lang.CommentLine) (string, int) {
+	var parts []string
+	first := lines[0]
+	end := first.Line
+
+	for _, cl := range lines[1:] {
+		if cl.Block != first.Block || cl.Line != end+1 {
+			break
+		}
+		text := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(cl.Text), "*/!"))
+		if text == "" || d.hasMarker(text) {
+			break
+		}
+		parts = append(parts, text)
+		end = cl.Line
 	}
 
-	return items, nil
+	return strings.Join(parts, " "), end
+}
+
+// hasMarker reports whether text contains any debt marker
+func (d *Detector) hasMarker(text string) bool {
+	for _, typeStr := range d.types {
+		if d.rules[typeStr].pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchableLines returns the text to match markers against: only comment
+// text when the language is known, every whole line otherwise
+func (d *Detector) searchableLines(language string, lines []string) []lang.CommentLine {
+	if l, ok := lang.Lookup(language); ok {
+		return lang.Comments(l, lines)
+	}
+
+	out := make([]lang.CommentLine, 0, len(lines))
+	for i, line := range lines {
+		out = append(out, lang.CommentLine{Line: i + 1, Text: line, Block: i + 1})
+	}
+	return out
+}
+
+// splitLines splits content into lines without their terminators
+func splitLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
 }
 
 // detectSeverity determines severity level based on type and message
 func (d *Detector) detectSeverity(typeStr, message string) int {
-	severity := d.typeMap[typeStr]
+	severity := d.rules[typeStr].severity
 
 	// Escalate severity for critical keywords
 	criticalKeywords := []string{
@@ -125,13 +555,11 @@ func (d *Detector) detectSeverity(typeStr, message string) int {
 	return severity
 }
 
-// generateID creates a unique ID for a debt item
-func (d *Detector) generateID(filePath string, lineNumber int) string {
-	hash := md5.Sum([]byte(fmt.Sprintf("%s:%d:%d", filePath, lineNumber, time.Now().Unix())))
-	return fmt.Sprintf("%x", hash)[:12]
-}
-
-// CalculateFrequency counts similar items in a list
+// CalculateFrequency counts the items of each type in one file and sets
+// their Frequency.
+//
+// Deprecated: Use ClusterItems, which groups similar items across the
+// repository.
 func (d *Detector) CalculateFrequency(items []models.DebtItem, filePath string) map[string]int {
 	frequencies := make(map[string]int)
 
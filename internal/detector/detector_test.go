@@ -1,58 +1,58 @@
 package detector
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"tech-debt-collector/internal/models"
 )
 
 func TestDetectorFindsTODO(t *testing.T) {
 	d := NewDetector()
-	
+
 	tests := []struct {
+		name     string
 		input    string
 		expected string
 	}{
-		{"// TODO: fix this", "TODO"},
-		{"// FIXME: bug here", "FIXME"},
-		{"# HACK: temporary solution", "HACK"},
-		{"// XXX: security issue", "XXX"},
+		{"todo.go", "// TODO: fix this", "TODO"},
+		{"fixme.go", "// FIXME: bug here", "FIXME"},
+		{"hack.py", "# HACK: temporary solution", "HACK"},
+		{"xxx.go", "// XXX: security issue", "XXX"},
 	}
 
 	for _, tt := range tests {
 		// Write test file
-		tmpFile := "/tmp/test_" + tt.expected + ".go"
-		content := tt.input
-		err := writeTestFile(tmpFile, content)
-		assert.NoError(t, err)
+		tmpFile := writeTestFile(t, tt.name, tt.input)
 
 		// Scan file
-		items, err := d.Detect(tmpFile)
+		items, err := d.DetectInFile(tmpFile, 3)
 		assert.NoError(t, err)
-		assert.Greater(t, len(items), 0)
-		assert.Equal(t, tt.expected, items[0].Type)
+		if assert.NotEmpty(t, items, tt.input) {
+			assert.Equal(t, tt.expected, items[0].Type)
+		}
 	}
 }
 
-func TestDetectorExtracts Message(t *testing.T) {
+func TestDetectorExtractsMessage(t *testing.T) {
 	d := NewDetector()
-	tmpFile := "/tmp/test_message.go"
-	content := "// TODO: implement authentication logic"
-	
-	writeTestFile(tmpFile, content)
-	items, _ := d.Detect(tmpFile)
-
-	assert.NotEmpty(t, items[0].Comment)
-	assert.Contains(t, items[0].Comment, "authentication")
+	tmpFile := writeTestFile(t, "message.go", "// TODO: implement authentication logic")
+
+	items, err := d.DetectInFile(tmpFile, 3)
+	assert.NoError(t, err)
+	if !assert.NotEmpty(t, items) {
+		return
+	}
+	assert.Contains(t, items[0].Message, "authentication")
 }
 
 func TestDetectorSeverityDetection(t *testing.T) {
 	d := NewDetector()
-	
+
 	tests := []struct {
-		comment  string
-		minSev   int
+		comment string
+		minSev  int
 	}{
 		{"TODO: nice to have", 1},
 		{"FIXME: critical security issue", 5},
@@ -60,25 +60,26 @@ func TestDetectorSeverityDetection(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		tmpFile := "/tmp/test_sev.go"
-		content := "// " + tt.comment
-		writeTestFile(tmpFile, content)
-		
-		items, _ := d.Detect(tmpFile)
-		assert.GreaterOrEqual(t, items[0].Severity, tt.minSev)
+		tmpFile := writeTestFile(t, "sev.go", "// "+tt.comment)
+
+		items, err := d.DetectInFile(tmpFile, 3)
+		assert.NoError(t, err)
+		if assert.NotEmpty(t, items, tt.comment) {
+			assert.GreaterOrEqual(t, items[0].Severity, tt.minSev)
+		}
 	}
 }
 
 func TestDetectorFrequency(t *testing.T) {
 	d := NewDetector()
-	tmpFile := "/tmp/test_freq.go"
 	content := `// TODO: fix 1
 	// TODO: fix 2
 	// TODO: fix 3
 	// FIXME: bug`
-	
-	writeTestFile(tmpFile, content)
-	items, _ := d.Detect(tmpFile)
+	tmpFile := writeTestFile(t, "freq.go", content)
+
+	items, err := d.DetectInFile(tmpFile, 3)
+	assert.NoError(t, err)
 
 	d.CalculateFrequency(items, tmpFile)
 
@@ -93,6 +94,11 @@ func TestDetectorFrequency(t *testing.T) {
 	assert.Equal(t, 3, todoCount)
 }
 
-func writeTestFile(path, content string) error {
-	return writeToFile(path, content)
+// writeTestFile writes content to name in a temporary directory and returns
+// its path
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
 }
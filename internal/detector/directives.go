@@ -0,0 +1,119 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"tech-debt-collector/internal/lang"
+	"tech-debt-collector/internal/models"
+)
+
+// Directives understood in comments
+const (
+	DirectiveIgnore         = "ignore"           // Items on this line, or just above in the same comment
+	DirectiveIgnoreNextLine = "ignore-next-line" // Items starting on the next line
+	DirectiveSnooze         = "snooze"           // Like ignore, until a date
+)
+
+var (
+	directiveRe = regexp.MustCompile(`\btechdebt:(ignore-next-line|ignore|snooze)\b(.*)$`)
+	untilRe     = regexp.MustCompile(`\buntil=(\d{4}-\d{2}-\d{2})\b`)
+	reasonRe    = regexp.MustCompile(`\breason=(?:"([^"]*)"|(\S+))`)
+)
+
+// directive is a techdebt: comment found at Line of comment Block
+type directive struct {
+	Line, Block int
+	Alone       bool // Nothing else is on the line, so it may annotate the item above
+	Kind        string
+	Until       *time.Time
+	Reason      string
+}
+
+// extractDirectives removes techdebt: directives and their arguments from
+// the comment text, so they don't leak into messages, and returns them.
+// A snooze without a valid until= date is ignored rather than applied
+// forever.
+func extractDirectives(lines []lang.CommentLine) []directive {
+	var out []directive
+	for i := range lines {
+		loc := directiveRe.FindStringSubmatchIndex(lines[i].Text)
+		if loc == nil {
+			continue
+		}
+		text := lines[i].Text
+		kind, args := text[loc[2]:loc[3]], text[loc[4]:loc[5]]
+		lines[i].Text = text[:loc[0]]
+
+		dir := directive{Line: lines[i].Line, Block: lines[i].Block, Kind: kind}
+		dir.Alone = strings.TrimSpace(strings.Trim(lines[i].Text, " \t*/#-!")) == ""
+		if m := reasonRe.FindStringSubmatch(args); m != nil {
+			dir.Reason = m[1] + m[2]
+		}
+		if kind == DirectiveSnooze {
+			m := untilRe.FindStringSubmatch(args)
+			if m == nil {
+				continue
+			}
+			until, err := time.Parse(dateLayout, m[1])
+			if err != nil {
+				continue
+			}
+			dir.Until = &until
+		}
+		out = append(out, dir)
+	}
+	return out
+}
+
+// applyDirectives marks the items a directive applies to. blocks maps a
+// comment line to its comment block. Snoozes that have run out leave the item active,
+// one severity level higher.
+func applyDirectives(items []models.DebtItem, dirs []directive, blocks map[int]int, now time.Time) {
+	for i := range items {
+		item := &items[i]
+		for _, dir := range dirs {
+			if !dir.appliesTo(item, blocks) {
+				continue
+			}
+			item.Suppression = &models.Suppression{
+				Directive: dir.Kind,
+				Line:      dir.Line,
+				Until:     dir.Until,
+				Reason:    dir.Reason,
+			}
+			if dir.Until != nil && !now.Before(*dir.Until) {
+				item.Suppression.Expired = true
+				item.Severity = min(item.Severity+1, 5)
+			}
+			break
+		}
+	}
+}
+
+func (dir directive) appliesTo(item *models.DebtItem, blocks map[int]int) bool {
+	if dir.Kind == DirectiveIgnoreNextLine {
+		return item.LineNumber == dir.Line+1
+	}
+	end := max(item.EndLine, item.LineNumber)
+	if dir.Line >= item.LineNumber && dir.Line <= end {
+		return true
+	}
+	// A directive on its own line right below the item's comment
+	block, ok := blocks[end]
+	return dir.Alone && dir.Line == end+1 && ok && block == dir.Block
+}
+
+// SplitSuppressed separates items silenced by a directive from the active
+// ones. Items whose snooze has expired are active again.
+func SplitSuppressed(items []models.DebtItem) (active, suppressed []models.DebtItem) {
+	for _, item := range items {
+		if item.Suppression != nil && !item.Suppression.Expired {
+			suppressed = append(suppressed, item)
+		} else {
+			active = append(active, item)
+		}
+	}
+	return active, suppressed
+}
@@ -0,0 +1,57 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"tech-debt-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectives(t *testing.T) {
+	src := `package x
+
+// TODO: keep for now techdebt:ignore
+// techdebt:ignore-next-line
+x() // FIXME: known
+// HACK: vendor workaround
+// techdebt:snooze until=2026-11-01 reason="waiting on vendor"
+// TODO: old snooze techdebt:snooze until=2026-01-01
+// XXX: still active
+// TODO: bad snooze techdebt:snooze reason=nodate
+`
+	d := NewDetector()
+	d.Now = func() time.Time { return time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC) }
+	items, err := d.DetectInReader("x.go", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	assert.Len(t, items, 6)
+
+	byLine := make(map[int]models.DebtItem)
+	for _, item := range items {
+		byLine[item.LineNumber] = item
+	}
+
+	assert.Equal(t, "keep for now", byLine[3].Message, "directives are not part of the message")
+	assert.Equal(t, DirectiveIgnore, byLine[3].Suppression.Directive)
+	assert.Equal(t, DirectiveIgnoreNextLine, byLine[5].Suppression.Directive)
+
+	hack := byLine[6]
+	assert.Equal(t, "vendor workaround", hack.Message)
+	assert.Equal(t, DirectiveSnooze, hack.Suppression.Directive)
+	assert.Equal(t, "waiting on vendor", hack.Suppression.Reason)
+	assert.Equal(t, 7, hack.Suppression.Line)
+	assert.False(t, hack.Suppression.Expired)
+
+	expired := byLine[8]
+	assert.True(t, expired.Suppression.Expired)
+	assert.Equal(t, 3, expired.Severity, "expired snoozes come back one level higher")
+
+	assert.Nil(t, byLine[9].Suppression)
+	assert.Nil(t, byLine[10].Suppression, "a snooze without a date does not apply")
+
+	active, suppressed := SplitSuppressed(items)
+	assert.Len(t, suppressed, 3)
+	assert.Len(t, active, 3)
+}
@@ -0,0 +1,74 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+
+	"tech-debt-collector/internal/clones"
+	"tech-debt-collector/internal/lang"
+	"tech-debt-collector/internal/models"
+)
+
+// TypeDuplication marks code copied to several places
+const TypeDuplication = "DUPLICATION"
+
+// duplicationSeverities scale with the clone's length in lines: the first
+// entry whose line count is reached applies. Four or more copies add one.
+var duplicationSeverities = []struct{ lines, severity int }{
+	{60, 4},
+	{25, 3},
+	{0, 2},
+}
+
+// duplication reports runs of tokens repeated across the repository's
+// source files, ignoring whitespace and comments
+type duplication struct {
+	index *clones.Index
+}
+
+func (dup duplication) Collect(src *source) bool {
+	if _, ok := codeKeywords[src.Language]; !ok {
+		return false // Only programming languages; data files repeat by design
+	}
+	l, _ := lang.Lookup(src.Language)
+	return dup.index.Add(src.Path, lang.Code(l, src.Lines))
+}
+
+func (dup duplication) Finish() []models.DebtItem {
+	groups := dup.index.Groups()
+	dup.index.Reset()
+
+	items := make([]models.DebtItem, 0, len(groups))
+	for _, g := range groups {
+		first := g.Locations[0]
+		lines := first.EndLine - first.StartLine + 1
+
+		item := models.DebtItem{
+			FilePath:   first.Path,
+			LineNumber: first.StartLine,
+			EndLine:    first.EndLine,
+			Type:       TypeDuplication,
+			Category:   models.CategoryDuplication,
+		}
+		var others []string
+		for _, loc := range g.Locations {
+			item.Locations = append(item.Locations, models.Location{FilePath: loc.Path, StartLine: loc.StartLine, EndLine: loc.EndLine})
+			if loc != first {
+				others = append(others, fmt.Sprintf("%s:%d-%d", loc.Path, loc.StartLine, loc.EndLine))
+			}
+		}
+		item.Message = fmt.Sprintf("%d lines (%d tokens) duplicated in %s", lines, g.Tokens, strings.Join(others, ", "))
+
+		for _, s := range duplicationSeverities {
+			if lines >= s.lines {
+				item.Severity = s.severity
+				break
+			}
+		}
+		if len(g.Locations) >= 4 {
+			item.Severity++
+		}
+		items = append(items, item)
+	}
+	return items
+}
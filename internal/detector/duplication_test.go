@@ -0,0 +1,104 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/config"
+	"tech-debt-collector/internal/models"
+)
+
+func TestDuplication(t *testing.T) {
+	var shared strings.Builder
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&shared, "\ttotal%d := price(item, %d) * rate // step %d\n", i, i, i)
+	}
+	files := map[string]string{
+		"a.go":      "package a\n\nfunc a() {\n" + shared.String() + "}\n",
+		"b/b.go":    "package b\n\n// Copied from a\nfunc b() {\n" + strings.ReplaceAll(shared.String(), "// step", "// copied step") + "}\n",
+		"c.go":      "package c\n\nfunc c() {\n\tother()\n}\n",
+		"data.yaml": strings.Repeat("key: value\n", 200) + strings.Repeat("key: value\n", 200),
+	}
+
+	d := NewDetector()
+	d.Begin()
+	for _, path := range []string{"a.go", "b/b.go", "c.go", "data.yaml"} {
+		_, err := d.DetectInReader(path, strings.NewReader(files[path]), 3)
+		assert.NoError(t, err)
+	}
+
+	var dups []models.DebtItem
+	for _, item := range d.Finish() {
+		if item.Type == TypeDuplication {
+			dups = append(dups, item)
+		}
+	}
+	if !assert.Len(t, dups, 1) {
+		return
+	}
+	item := dups[0]
+	assert.Equal(t, "a.go", item.FilePath)
+	assert.Equal(t, 3, item.LineNumber)
+	assert.Equal(t, 34, item.EndLine)
+	assert.Equal(t, models.CategoryDuplication, item.Category)
+	assert.Equal(t, 3, item.Severity)
+	assert.NotEmpty(t, item.ID)
+	assert.Equal(t, []models.Location{
+		{FilePath: "a.go", StartLine: 3, EndLine: 34},
+		{FilePath: "b/b.go", StartLine: 4, EndLine: 35},
+	}, item.Locations)
+	assert.Contains(t, item.Message, "32 lines")
+	assert.Contains(t, item.Message, "in b/b.go:4-35")
+}
+
+func TestDuplicationConfig(t *testing.T) {
+	src := "package a\n\nfunc f() {\n" + strings.Repeat("\tx = append(x, y)\n", 10) + "}\n"
+
+	count := func(cfg *config.Config) int {
+		d, err := NewDetectorWithConfig(cfg)
+		assert.NoError(t, err)
+		d.Begin()
+		d.DetectInReader("a.go", strings.NewReader(src), 3)
+		d.DetectInReader("b.go", strings.NewReader(src), 3)
+		n := 0
+		for _, item := range d.Finish() {
+			if item.Type == TypeDuplication {
+				n++
+			}
+		}
+		return n
+	}
+
+	assert.Equal(t, 0, count(&config.Config{}), "below the default length")
+	assert.Equal(t, 1, count(&config.Config{Duplication: config.DuplicationRules{MinTokens: 40}}))
+	assert.Equal(t, 0, count(&config.Config{Duplication: config.DuplicationRules{MinTokens: 40, Disabled: true}}))
+}
+
+func TestRepoAnalysesNeedASession(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&body, "\tx%d = append(x, y%d)\n", i, i)
+	}
+	src := "package a\n\nfunc f() {\n" + body.String() + "}\n"
+	cfg := &config.Config{Duplication: config.DuplicationRules{MinTokens: 40}}
+	d, err := NewDetectorWithConfig(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// One-shot detection keeps nothing for later
+	d.DetectInReader("a.go", strings.NewReader(src), 3)
+	d.DetectInReader("b.go", strings.NewReader(src), 3)
+	assert.Empty(t, d.Finish())
+
+	// A file scanned twice in a session isn't a copy of itself
+	d.Begin()
+	d.DetectInReader("a.go", strings.NewReader(src), 3)
+	d.DetectInReader("a.go", strings.NewReader(src), 3)
+	for _, item := range d.Finish() {
+		assert.NotEqual(t, TypeDuplication, item.Type)
+	}
+}
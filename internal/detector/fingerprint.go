@@ -0,0 +1,80 @@
+package detector
+
+import (
+	"crypto/md5"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"tech-debt-collector/internal/models"
+	"tech-debt-collector/internal/scanner"
+)
+
+// assignIDs gives the items found in one file stable fingerprint IDs.
+//
+// A fingerprint hashes the file path relative to Root, the item type, the
+// normalized message, and the normalized text of the item's first line and
+// of the nearest non-blank line after it: the code a comment annotates.
+// Line numbers are left out, so edits elsewhere in the file keep IDs.
+//
+// Identical items in one file (same type, message and context) would collide.
+// The nth duplicate in file order therefore also hashes its ordinal: the first
+// keeps the plain fingerprint, and later ones keep theirs for as long as the
+// duplicates stay in the same relative order.
+func (d *Detector) assignIDs(filePath string, lines []string, items []models.DebtItem) {
+	path := d.fingerprintPath(filePath)
+	seen := make(map[string]int)
+
+	for i := range items {
+		item := &items[i]
+		key := strings.Join([]string{
+			path,
+			item.Type,
+			normalizeText(item.Message),
+			normalizeText(nextLine(lines, item.LineNumber-1)),
+			normalizeText(nextLine(lines, max(item.EndLine, item.LineNumber))),
+		}, "\x00")
+
+		n := seen[key]
+		seen[key]++
+		if n > 0 {
+			key = fmt.Sprintf("%s\x00%d", key, n)
+		}
+		item.ID = fingerprint(key)
+	}
+}
+
+// fingerprintPath makes filePath independent of the checkout location
+func (d *Detector) fingerprintPath(filePath string) string {
+	if i := strings.LastIndex(filePath, scanner.ArchiveSeparator); i >= 0 {
+		filePath = filePath[i+len(scanner.ArchiveSeparator):]
+	} else if d.Root != "" {
+		if rel, err := filepath.Rel(d.Root, filePath); err == nil {
+			filePath = rel
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(filePath)), "./")
+}
+
+// nextLine returns the first non-blank line from lines[i] on, or "" at the
+// end of the file
+func nextLine(lines []string, i int) string {
+	for ; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			return lines[i]
+		}
+	}
+	return ""
+}
+
+// normalizeText lower-cases text and collapses whitespace, so reformatting
+// and re-indenting keep fingerprints
+func normalizeText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// fingerprint hashes a key into a short hex ID
+func fingerprint(key string) string {
+	hash := md5.Sum([]byte(key))
+	return fmt.Sprintf("%x", hash)[:12]
+}
@@ -0,0 +1,53 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDsSurviveUnrelatedEdits(t *testing.T) {
+	d := NewDetector()
+	detect := func(path, src string) []string {
+		items, err := d.DetectInReader(path, strings.NewReader(src), 3)
+		assert.NoError(t, err)
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	src := "package x\n\n// TODO: cache lookups\nfunc lookup() {}\n"
+	ids := detect("pkg/x.go", src)
+	assert.Len(t, ids, 1)
+
+	assert.Equal(t, ids, detect("pkg/x.go", src), "IDs are the same on every run")
+	assert.Equal(t, ids, detect("pkg/x.go", "package x\n\nimport \"fmt\"\n\n\n// TODO:   Cache lookups\nfunc lookup() {}\n"),
+		"shifted lines and whitespace or case changes keep the ID")
+	assert.NotEqual(t, ids, detect("pkg/y.go", src))
+	assert.NotEqual(t, ids, detect("pkg/x.go", "package x\n\n// TODO: cache lookups\nfunc find() {}\n"),
+		"the code the item sits on is part of its identity")
+
+	d.Root = "/src/repo"
+	assert.Equal(t, ids, detect("/src/repo/pkg/x.go", src))
+	assert.Equal(t, ids, detect("release.zip!/pkg/x.go", src))
+}
+
+func TestIdenticalItemsGetDistinctIDs(t *testing.T) {
+	src := "// TODO: retry\ny()\n// TODO: retry\ny()\n// TODO: retry\ny()\n"
+
+	items, err := NewDetector().DetectInReader("a.go", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.NotEqual(t, items[0].ID, items[1].ID)
+	assert.NotEqual(t, items[1].ID, items[2].ID)
+	assert.NotEqual(t, items[0].ID, items[2].ID)
+
+	// Removing the first duplicate gives the survivors the leading IDs
+	rest, err := NewDetector().DetectInReader("a.go", strings.NewReader(strings.Replace(src, "// TODO: retry\n", "", 1)), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, items[0].ID, rest[0].ID)
+	assert.Equal(t, items[1].ID, rest[1].ID)
+}
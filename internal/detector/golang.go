@@ -0,0 +1,61 @@
+package detector
+
+import (
+	"path"
+	"path/filepath"
+
+	"tech-debt-collector/internal/goast"
+	"tech-debt-collector/internal/models"
+)
+
+// goStructure runs the Go AST analyzer on Go files. Files that don't parse,
+// such as templates or broken fixtures, are left to the other analyzers.
+type goStructure struct {
+	analyzer *goast.Analyzer
+}
+
+func (g goStructure) Analyze(src *source) []models.DebtItem {
+	if src.Language != "go" {
+		return nil
+	}
+	items, err := g.analyzer.AnalyzeFile(src.Path, src.Data)
+	if err != nil {
+		return nil
+	}
+	return items
+}
+
+// goDeprecations reports references to Go symbols documented as
+// deprecated, in any package of the repository
+type goDeprecations struct {
+	deprecations *goast.Deprecations
+}
+
+func (g goDeprecations) Collect(src *source) bool {
+	if path.Base(filepath.ToSlash(src.Path)) == "go.mod" {
+		return g.deprecations.AddModule(src.Path, src.Data)
+	}
+	return src.Language == "go" && g.deprecations.Add(src.Path, src.Data) == nil
+}
+
+func (g goDeprecations) Finish() []models.DebtItem {
+	items := g.deprecations.Usages()
+	g.deprecations.Reset()
+	return items
+}
+
+// goDocs reports exported Go API without a proper doc comment, and keeps
+// each package's documentation coverage
+type goDocs struct {
+	docs *goast.Docs
+}
+
+func (g goDocs) Collect(src *source) bool {
+	return src.Language == "go" && g.docs.Add(src.Path, src.Rel, src.Data)
+}
+
+func (g goDocs) Finish() []models.DebtItem {
+	items := g.docs.Findings()
+	g.docs.Reset()
+	return items
+}
@@ -0,0 +1,47 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/models"
+)
+
+func TestDocCoverageIsRelativeToRoot(t *testing.T) {
+	d := NewDetector()
+	d.Root = "/src/repo"
+	d.Begin()
+	_, err := d.DetectInReader("/src/repo/pkg/store/store.go", strings.NewReader("package store\n\n// Open opens.\nfunc Open() {}\n"), 3)
+	assert.NoError(t, err)
+
+	var missing []string
+	for _, item := range d.Finish() {
+		if item.Category == models.CategoryDocs {
+			missing = append(missing, item.FilePath)
+		}
+	}
+	assert.Equal(t, []string{"/src/repo/pkg/store/store.go"}, missing)
+	assert.Equal(t, []models.PackageDocs{
+		{Package: "pkg/store", Name: "store", Exported: 2, Documented: 1, Coverage: 50},
+	}, d.DocCoverage())
+}
+
+func TestDocsTestdataIsRelativeToRoot(t *testing.T) {
+	d := NewDetector()
+	d.Root = "/src/testdata/repo"
+	d.Begin()
+	for _, name := range []string{"pkg/store/store.go", "pkg/store/testdata/fixture.go"} {
+		_, err := d.DetectInReader(d.Root+"/"+name, strings.NewReader("// Package store keeps things.\npackage store\n\nfunc Open() {}\n"), 3)
+		assert.NoError(t, err)
+	}
+
+	var missing []string
+	for _, item := range d.Finish() {
+		if item.Category == models.CategoryDocs {
+			missing = append(missing, item.FilePath)
+		}
+	}
+	assert.Equal(t, []string{"/src/testdata/repo/pkg/store/store.go"}, missing)
+}
@@ -0,0 +1,14 @@
+package detector
+
+import (
+	"tech-debt-collector/internal/iac"
+	"tech-debt-collector/internal/models"
+)
+
+// infrastructure runs the infrastructure-as-code rules on Dockerfiles,
+// compose files and Kubernetes manifests
+type infrastructure struct{}
+
+func (infrastructure) Analyze(src *source) []models.DebtItem {
+	return iac.Analyze(src.Path, src.Language, src.Lines)
+}
@@ -0,0 +1,25 @@
+package detector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/iac"
+)
+
+// TestRepositoryComposeFile checks the credentials written into the
+// repository's own docker-compose.yml: each is reported once, as an
+// infrastructure item, whatever its value looks like
+func TestRepositoryComposeFile(t *testing.T) {
+	items, err := NewDetector().DetectInFile("../../docker-compose.yml", 3)
+	if !assert.NoError(t, err) {
+		return
+	}
+	byLine := make(map[int][]string)
+	for _, item := range items {
+		byLine[item.LineNumber] = append(byLine[item.LineNumber], item.Type)
+	}
+	assert.Equal(t, []string{iac.TypeHardcodedCredential}, byLine[14], "QDRANT_API_KEY=tech-debt-secret")
+	assert.Equal(t, []string{iac.TypeHardcodedCredential}, byLine[45], "GF_SECURITY_ADMIN_PASSWORD=admin")
+}
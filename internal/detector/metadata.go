@@ -0,0 +1,74 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// dateLayout is the only due date format understood, e.g. 2026-12-01
+const dateLayout = "2006-01-02"
+
+var (
+	issueRefRe  = regexp.MustCompile(`^(#\d+|[A-Z][A-Z0-9]+-\d+|https?://\S+)$`)
+	dateRe      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	versionRe   = regexp.MustCompile(`^v\d+(\.\d+)*$`)
+	ownerRe     = regexp.MustCompile(`^@?[\w.-]+$`)
+	deadlineRe  = regexp.MustCompile(`(?i)\b(?:by|until|before)\s+(\d{4}-\d{2}-\d{2}|v\d+(?:\.\d+)*)\b`)
+	tagCloserOf = map[byte]byte{'(': ')', '[': ']'}
+)
+
+// metadata is what a marker says about who owns it and when it is due
+type metadata struct {
+	Owner      string
+	IssueRefs  []string
+	DueDate    *time.Time
+	DueVersion string
+}
+
+// parseMetadata reads the tag that may follow a marker, as in
+// TODO(alice, PROJ-123, 2026-12-01), TODO(#123) or FIXME[JIRA-42], plus
+// "by 2026-01-01" or "until v2.0" deadlines in the text. It returns the
+// metadata and the message without the tag. The tag must be attached to the
+// marker, so "TODO: (optional) cleanup" has none.
+func parseMetadata(message string, attached bool) (metadata, string) {
+	var meta metadata
+
+	if attached && message != "" {
+		if closer, ok := tagCloserOf[message[0]]; ok {
+			if end := strings.IndexByte(message, closer); end > 0 {
+				for _, part := range strings.Split(message[1:end], ",") {
+					meta.add(strings.TrimSpace(part))
+				}
+				message = strings.TrimSpace(strings.TrimLeft(message[end+1:], ": \t"))
+			}
+		}
+	}
+
+	if meta.DueDate == nil && meta.DueVersion == "" {
+		if m := deadlineRe.FindStringSubmatch(message); m != nil {
+			meta.add(m[1])
+		}
+	}
+
+	return meta, message
+}
+
+// add classifies one tag entry. The first name-like entry is the owner.
+func (m *metadata) add(part string) {
+	switch {
+	case part == "":
+	case issueRefRe.MatchString(part):
+		m.IssueRefs = append(m.IssueRefs, part)
+	case dateRe.MatchString(part):
+		if t, err := time.Parse(dateLayout, part); err == nil && m.DueDate == nil {
+			m.DueDate = &t
+		}
+	case versionRe.MatchString(part):
+		if m.DueVersion == "" {
+			m.DueVersion = part
+		}
+	case ownerRe.MatchString(part) && m.Owner == "":
+		m.Owner = strings.TrimPrefix(part, "@")
+	}
+}
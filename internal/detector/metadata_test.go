@@ -0,0 +1,47 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		line    string
+		owner   string
+		issues  []string
+		due     string
+		version string
+		message string
+	}{
+		{"TODO(alice, PROJ-123, 2026-12-01): split this", "alice", []string{"PROJ-123"}, "2026-12-01", "", "split this"},
+		{"TODO(bob): rename", "bob", nil, "", "", "rename"},
+		{"TODO(#123) flaky", "", []string{"#123"}, "", "", "flaky"},
+		{"FIXME[JIRA-42]: null deref", "", []string{"JIRA-42"}, "", "", "null deref"},
+		{"TODO(@carol, v2.0)", "carol", nil, "", "v2.0", ""},
+		{"TODO: drop shim by 2026-01-01", "", nil, "2026-01-01", "", "drop shim by 2026-01-01"},
+		{"HACK keep until v2.0", "", nil, "", "v2.0", "keep until v2.0"},
+		{"TODO: (optional) cleanup", "", nil, "", "", "(optional) cleanup"},
+	}
+
+	d := NewDetector()
+	for _, tt := range tests {
+		items, err := d.DetectInReader("a.go", strings.NewReader("// "+tt.line+"\n"), 3)
+		assert.NoError(t, err)
+		if !assert.Len(t, items, 1, tt.line) {
+			continue
+		}
+		item := items[0]
+		assert.Equal(t, tt.owner, item.Owner, tt.line)
+		assert.Equal(t, tt.issues, item.IssueRefs, tt.line)
+		assert.Equal(t, tt.version, item.DueVersion, tt.line)
+		assert.Equal(t, tt.message, item.Message, tt.line)
+		if tt.due == "" {
+			assert.Nil(t, item.DueDate, tt.line)
+		} else if assert.NotNil(t, item.DueDate, tt.line) {
+			assert.Equal(t, tt.due, item.DueDate.Format(dateLayout), tt.line)
+		}
+	}
+}
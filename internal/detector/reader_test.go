@@ -0,0 +1,124 @@
+package detector
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/iac"
+)
+
+func TestDetectInReader(t *testing.T) {
+	d := NewDetector()
+
+	items, err := d.DetectInReader("buffer.go", strings.NewReader("package x\n\n// FIXME: unsaved change\n"), 4)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "FIXME", items[0].Type)
+	assert.Equal(t, "buffer.go", items[0].FilePath)
+	assert.Equal(t, 3, items[0].LineNumber)
+	assert.Equal(t, 4, items[0].FileImportance)
+	assert.Equal(t, "unsaved change", items[0].Message)
+}
+
+func TestDetectInFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pkg/a.go": {Data: []byte("// TODO: from memory\n")},
+	}
+
+	items, err := NewDetector().DetectInFS(fsys, "pkg/a.go", 3)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "pkg/a.go", items[0].FilePath)
+
+	_, err = NewDetector().DetectInFS(fsys, "missing.go", 3)
+	assert.Error(t, err)
+}
+
+func TestDetectUsesLanguageCommentSyntax(t *testing.T) {
+	d := NewDetector()
+	src := "#!/bin/sh\n# TODO: quote args\necho \"TODO\" // not a comment here\n"
+
+	items, err := d.DetectInReader("scripts/deploy", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "shell", items[0].Language)
+	assert.Equal(t, 2, items[0].LineNumber)
+
+	// Unknown languages fall back to matching whole lines
+	items, err = d.DetectInReaderAs("notes.txt", "", strings.NewReader("TODO: write notes\n"), 3)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "", items[0].Language)
+}
+
+// TestFalsePositiveCorpus checks that markers in identifiers, string literals
+// and ordinary words are not reported. Each file lists the only real items.
+func TestFalsePositiveCorpus(t *testing.T) {
+	type hit struct {
+		Line int
+		Type string
+	}
+	tests := map[string][]hit{
+		"identifiers.go": {{9, "TODO"}, {16, "FIXME"}, {17, "XXX"}},
+		"strings.py":     {{2, "TODO"}, {4, "HACK"}},
+		"deploy.sh":      {{6, "XXX"}},
+		"config.yaml":    {{4, "TODO"}},
+		"app.js":         {{6, "TODO"}},
+		"schema.sql":     {{3, "HACK"}, {4, "XXX"}},
+		"page.html":      {{2, "FIXME"}, {4, "HACK"}},
+		"Dockerfile":     {{1, "TODO"}, {2, iac.TypeUnpinnedImage}, {2, iac.TypeRootUser}, {2, iac.TypeMissingHealthcheck}},
+		"deprecated.go":  {{5, "DEPRECATED"}, {8, "DEPRECATED"}, {12, "DEPRECATED"}},
+		"deprecated.py":  {{3, "DEPRECATED"}},
+	}
+
+	d := NewDetector()
+	for name, want := range tests {
+		items, err := d.DetectInFile(filepath.Join("testdata", "corpus", name), 3)
+		assert.NoError(t, err)
+
+		var got []hit
+		for _, item := range items {
+			got = append(got, hit{item.LineNumber, item.Type})
+		}
+		assert.Equal(t, want, got, name)
+	}
+}
+
+func TestDetectMultiLineComments(t *testing.T) {
+	src := `package x
+
+// TODO: refactor this
+// causes a memory leak under load
+//
+// unrelated paragraph
+func f() {} // FIXME: trailing
+
+/*
+ * HACK: retry twice
+ * because the API is flaky
+ * XXX: separate item
+ */
+`
+	items, err := NewDetector().DetectInReader("x.go", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	assert.Len(t, items, 4)
+
+	assert.Equal(t, "TODO", items[0].Type)
+	assert.Equal(t, "refactor this causes a memory leak under load", items[0].Message)
+	assert.Equal(t, 3, items[0].LineNumber)
+	assert.Equal(t, 4, items[0].EndLine)
+	assert.Equal(t, 5, items[0].Severity, "keywords on continuation lines escalate severity")
+
+	assert.Equal(t, "FIXME", items[1].Type)
+	assert.Equal(t, 7, items[1].EndLine)
+
+	assert.Equal(t, "HACK", items[2].Type)
+	assert.Equal(t, "retry twice because the API is flaky", items[2].Message)
+	assert.Equal(t, 11, items[2].EndLine)
+	assert.Equal(t, "XXX", items[3].Type)
+	assert.Equal(t, 12, items[3].LineNumber)
+}
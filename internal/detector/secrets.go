@@ -0,0 +1,55 @@
+package detector
+
+import (
+	"tech-debt-collector/internal/iac"
+	"tech-debt-collector/internal/models"
+	"tech-debt-collector/internal/secrets"
+)
+
+// hardcodedSecrets reports keys, tokens and passwords written into any file,
+// and keeps their values in src so the detector masks them in every message
+type hardcodedSecrets struct {
+	scanner *secrets.Scanner
+}
+
+func (h hardcodedSecrets) Analyze(src *source) []models.DebtItem {
+	items, values := h.scanner.Scan(src.Rel, src.Language, src.Lines)
+	src.Secrets = values
+	return items
+}
+
+// mergeReportedCredentials folds the secrets reported on the line of an
+// infrastructure rule's credential item into that item, which takes the
+// higher severity and the secret's masked message. A credential in a
+// manifest is then reported the same way whatever its value.
+func mergeReportedCredentials(items []models.DebtItem) []models.DebtItem {
+	creds := make(map[int]int) // Index of the credential item on each line
+	for i, item := range items {
+		if item.Type == iac.TypeHardcodedCredential {
+			creds[item.LineNumber] = i
+		}
+	}
+	if len(creds) == 0 {
+		return items
+	}
+
+	merged := make(map[int]bool)
+	for i, item := range items {
+		c, ok := creds[item.LineNumber]
+		if !ok || item.Type != secrets.Type {
+			continue
+		}
+		cred := &items[c]
+		cred.Message += " (" + item.Message + ")"
+		cred.Severity = max(cred.Severity, item.Severity)
+		merged[i] = true
+	}
+
+	kept := items[:0]
+	for i, item := range items {
+		if !merged[i] {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,128 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/config"
+	"tech-debt-collector/internal/iac"
+	"tech-debt-collector/internal/models"
+	"tech-debt-collector/internal/secrets"
+)
+
+func TestHardcodedSecrets(t *testing.T) {
+	key := "AKIA" + "Q3EGZ7XK2M4N6P8R" // Split so this file doesn't report itself
+	src := strings.Join([]string{
+		"package aws",
+		"",
+		"// TODO: rotate " + key + " before release",
+		`const accessKey = "` + key + `"`,
+		`var fixturePassword = "Tr0ub4dor" // techdebt:ignore reason=fixture`,
+	}, "\n")
+
+	items, err := NewDetector().DetectInReader("aws.go", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	for _, item := range items {
+		assert.NotContains(t, item.Message, key)
+		assert.NotContains(t, item.Message, "Tr0ub4dor")
+	}
+
+	var found []models.DebtItem
+	for _, item := range items {
+		if item.Type == secrets.Type {
+			found = append(found, item)
+		}
+	}
+	if !assert.Len(t, found, 3) {
+		return
+	}
+	assert.Equal(t, 3, found[0].LineNumber, "the marker's comment is scanned too")
+	assert.Equal(t, 4, found[1].LineNumber)
+	assert.Equal(t, "AWS access key AKIA******** is hard-coded", found[1].Message)
+	assert.Equal(t, models.CategorySecurity, found[1].Category)
+	assert.Equal(t, 5, found[1].Severity)
+	assert.Nil(t, found[1].Suppression)
+	if assert.NotNil(t, found[2].Suppression) {
+		assert.Equal(t, "fixture", found[2].Suppression.Reason)
+	}
+
+	todo := items[0]
+	assert.Equal(t, "TODO", todo.Type)
+	assert.Equal(t, "rotate AKIA******** before release", todo.Message)
+}
+
+func TestHardcodedSecretsMergeIntoCredentialItems(t *testing.T) {
+	src := "services:\n  db:\n    image: postgres:16\n    healthcheck:\n      test: pg_isready\n    environment:\n      POSTGRES_PASSWORD: Tr0ub4dor\n"
+
+	found := func(cfg *config.Config) []string {
+		d, err := NewDetectorWithConfig(cfg)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		items, err := d.DetectInReader("docker-compose.yml", strings.NewReader(src), 3)
+		assert.NoError(t, err)
+		var got []string
+		for _, item := range items {
+			got = append(got, fmt.Sprintf("%s %d %s", item.Type, item.Severity, item.Message))
+		}
+		return got
+	}
+
+	plain := iac.TypeHardcodedCredential + " 4 service db sets POSTGRES_PASSWORD to a hard-coded value"
+	assert.Equal(t, []string{
+		iac.TypeHardcodedCredential + " 5 service db sets POSTGRES_PASSWORD to a hard-coded value (POSTGRES_PASSWORD is set to a hard-coded secret ********)",
+	}, found(&config.Config{}))
+	assert.Equal(t, []string{plain}, found(&config.Config{Secrets: config.SecretRules{Disabled: true}}))
+	assert.Equal(t, []string{plain}, found(&config.Config{Secrets: config.SecretRules{Paths: []string{"docker-compose.yml"}}}))
+}
+
+func TestSecretsMaskedInEveryMessage(t *testing.T) {
+	password := "Zq8vLw3n" + "Xk2pRt7y"
+	literal := "q7Vt3LxP9mZ2" + "kR8wN4sJ6hD1yB5c"
+	src := strings.Join([]string{
+		"package db",
+		"",
+		`// TODO: remove db_password = "` + password + `" before release`,
+		`// FIXME: the signature "` + literal + `" is shared by every tenant`,
+		"// HACK: " + password + " also opens the admin console",
+		`var apiToken = "` + password + `" // techdebt:ignore reason=` + password,
+	}, "\n")
+
+	items, err := NewDetector().DetectInReader("db.go", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	byType := make(map[string]models.DebtItem)
+	for _, item := range items {
+		assert.NotContains(t, item.Message, password)
+		assert.NotContains(t, item.Message, literal)
+		byType[item.Type] = item
+	}
+	assert.Equal(t, `remove db_password = "********" before release`, byType["TODO"].Message)
+	assert.Equal(t, `the signature "********" is shared by every tenant`, byType["FIXME"].Message)
+	assert.Equal(t, "******** also opens the admin console", byType["HACK"].Message, "values are masked across the file")
+
+	sup := items[len(items)-1].Suppression
+	if assert.NotNil(t, sup) {
+		assert.Equal(t, "********", sup.Reason)
+	}
+}
+
+func TestSecretPathsAreRelativeToRoot(t *testing.T) {
+	src := `var token = "ghp_` + "Q3EGZ7XK2M4N6P8RsT1vW9yB5cD7fH0jL2nP" + `"` // Split so this file doesn't report itself
+	d := NewDetector()
+	d.Root = "/src/testdata/repo"
+
+	var got []string
+	for _, name := range []string{"config.go", "testdata/config.go"} {
+		items, err := d.DetectInReader(d.Root+"/"+name, strings.NewReader("package config\n\n"+src+"\n"), 3)
+		assert.NoError(t, err)
+		for _, item := range items {
+			if item.Type == secrets.Type {
+				got = append(got, name)
+			}
+		}
+	}
+	assert.Equal(t, []string{"config.go"}, got)
+}
@@ -0,0 +1,157 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"tech-debt-collector/internal/lang"
+	"tech-debt-collector/internal/models"
+)
+
+// TypeSkippedTest marks a test that is disabled or expected to fail
+const TypeSkippedTest = "SKIPPED_TEST"
+
+// skippedTestSeverity is the default severity of a disabled test
+const skippedTestSeverity = 3
+
+// testSkip recognises one framework's way of disabling a test. The test's
+// name is either in the match itself, or found by looking for nameRe before
+// (enclosing) or after (decorated) the match.
+type testSkip struct {
+	languages []string
+	pattern   *regexp.Regexp // Named submatches "name" and "reason" are optional
+	nameRe    *regexp.Regexp // Submatch 1 is the test name
+	enclosing bool           // The skip is inside the test rather than decorating it
+}
+
+var (
+	goFunc        = regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?(\w+)`)
+	goTestName    = regexp.MustCompile(`^(?:Test|Benchmark|Fuzz)`)
+	pyTestFunc    = regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)\s*\(`)
+	jvmTestMethod = regexp.MustCompile(`\b(?:void|fun|class)\s+(\w+)`)
+	rustTestFn    = regexp.MustCompile(`\bfn\s+(\w+)`)
+	// reasonArg pulls the first string literal or reason= argument
+	reasonArg = regexp.MustCompile(`(?:reason\s*=\s*)?(?:"([^"]*)"|'([^']*)')`)
+
+	testSkips = []testSkip{
+		{[]string{"go"}, regexp.MustCompile(`\b[tb]\.Skip(?:f|Now)?\((?P<reason>.*)\)`), goFunc, true},
+		{[]string{"javascript", "typescript"}, regexp.MustCompile("^\\s*(?:x(?:it|test|describe)|(?:it|test|describe)\\.skip)\\s*\\(\\s*[\"'`](?P<name>[^\"'`]*)"), nil, false},
+		{[]string{"python"}, regexp.MustCompile(`^\s*@(?:pytest\.mark\.(?:skip|skipif|xfail)|unittest\.(?:skip|skipIf|skipUnless|expectedFailure))\b(?P<reason>.*)`), pyTestFunc, false},
+		{[]string{"python"}, regexp.MustCompile(`^\s+pytest\.(?:skip|xfail)\((?P<reason>.*)\)`), pyTestFunc, true},
+		{[]string{"java", "kotlin", "groovy"}, regexp.MustCompile(`^\s*@(?:Disabled|Ignore)\b(?P<reason>.*)`), jvmTestMethod, false},
+		{[]string{"rust"}, regexp.MustCompile(`^\s*#\[ignore\b(?P<reason>.*)\]`), rustTestFn, false},
+	}
+)
+
+// skippedTests reports disabled tests, which silently stop guarding the
+// code they cover
+type skippedTests struct{}
+
+func (skippedTests) Analyze(src *source) []models.DebtItem {
+	if src.Language == "go" && !strings.HasSuffix(src.Path, "_test.go") {
+		return nil
+	}
+
+	var items []models.DebtItem
+	l, _ := lang.Lookup(src.Language)
+	for _, skip := range testSkips {
+		if !slices.Contains(skip.languages, src.Language) {
+			continue
+		}
+		for i, line := range src.Lines {
+			if isCommentLine(l, line) {
+				continue
+			}
+			m := skip.pattern.FindStringSubmatch(line)
+			if m == nil || shortModeSkip(src, i) {
+				continue
+			}
+
+			name := submatch(skip.pattern, m, "name")
+			if name == "" && skip.nameRe != nil {
+				name = skip.findName(src.Lines, i)
+			}
+			if src.Language == "go" && !goTestName.MatchString(name) {
+				continue // A helper checking the environment, e.g. for an installed tool
+			}
+			reason := ""
+			if r := reasonArg.FindStringSubmatch(submatch(skip.pattern, m, "reason")); r != nil {
+				reason = r[1] + r[2]
+			}
+
+			message := fmt.Sprintf("test %s is skipped", name)
+			if name == "" {
+				message = "test is skipped"
+			}
+			if reason != "" {
+				message += ": " + reason
+			}
+			items = append(items, models.DebtItem{
+				LineNumber: i + 1,
+				Type:       TypeSkippedTest,
+				Category:   models.CategoryTest,
+				Message:    message,
+				TestName:   name,
+				SkipReason: reason,
+				Severity:   skippedTestSeverity,
+			})
+		}
+	}
+	return items
+}
+
+// findName looks up the enclosing test above line i, or the decorated
+// test just below it
+func (skip testSkip) findName(lines []string, i int) string {
+	if skip.enclosing {
+		for j := i; j >= 0; j-- {
+			if m := skip.nameRe.FindStringSubmatch(lines[j]); m != nil {
+				return m[1]
+			}
+		}
+		return ""
+	}
+	for j := i; j < len(lines) && j <= i+5; j++ {
+		if m := skip.nameRe.FindStringSubmatch(lines[j]); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// shortModeSkip reports whether a Go skip at line i is guarded by
+// testing.Short(), the standard way to keep slow tests out of quick runs
+func shortModeSkip(src *source, i int) bool {
+	if src.Language != "go" {
+		return false
+	}
+	return strings.Contains(src.Lines[i], "testing.Short()") ||
+		i > 0 && strings.Contains(src.Lines[i-1], "testing.Short()")
+}
+
+// isCommentLine reports whether line starts with one of the language's
+// comment openers, i.e. is commented out
+func isCommentLine(l lang.Language, line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, tok := range l.LineComments {
+		if strings.HasPrefix(trimmed, tok) {
+			return true
+		}
+	}
+	for _, pair := range l.BlockComments {
+		if strings.HasPrefix(trimmed, pair[0]) || strings.HasPrefix(trimmed, "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// submatch returns the named submatch of m, or ""
+func submatch(re *regexp.Regexp, m []string, name string) string {
+	if i := re.SubexpIndex(name); i > 0 {
+		return m[i]
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkippedTests(t *testing.T) {
+	tests := []struct {
+		path   string
+		src    string
+		line   int
+		name   string
+		reason string
+	}{
+		{"a_test.go", "package a\n\nfunc TestRetry(t *testing.T) {\n\tt.Skip(\"flaky on CI\")\n}\n", 4, "TestRetry", "flaky on CI"},
+		{"a_test.go", "func TestX(t *testing.T) {\n\tt.SkipNow()\n}\n", 2, "TestX", ""},
+		{"a.test.js", "it.skip('renders the header', () => {})\n", 1, "renders the header", ""},
+		{"a.test.ts", "  xdescribe(\"checkout\", () => {})\n", 1, "checkout", ""},
+		{"test_a.py", "@pytest.mark.skip(reason=\"needs network\")\ndef test_fetch():\n    pass\n", 1, "test_fetch", "needs network"},
+		{"test_a.py", "@pytest.mark.xfail\ndef test_parse():\n    pass\n", 1, "test_parse", ""},
+		{"test_a.py", "def test_gpu():\n    pytest.skip(\"no GPU\")\n", 2, "test_gpu", "no GPU"},
+		{"ATest.java", "  @Disabled(\"until JIRA-42\")\n  @Test\n  void parsesDates() {}\n", 1, "parsesDates", "until JIRA-42"},
+		{"ATest.java", "  @Ignore\n  public void legacy() {}\n", 1, "legacy", ""},
+		{"lib.rs", "#[test]\n#[ignore = \"slow\"]\nfn big_input() {}\n", 2, "big_input", "slow"},
+	}
+
+	d := NewDetector()
+	for _, tt := range tests {
+		items, err := d.DetectInReader(tt.path, strings.NewReader(tt.src), 3)
+		assert.NoError(t, err)
+		if !assert.Len(t, items, 1, tt.src) {
+			continue
+		}
+		assert.Equal(t, TypeSkippedTest, items[0].Type, tt.src)
+		assert.Equal(t, tt.line, items[0].LineNumber, tt.src)
+		assert.Equal(t, tt.name, items[0].TestName, tt.src)
+		assert.Equal(t, tt.reason, items[0].SkipReason, tt.src)
+	}
+}
+
+func TestSkippedTestsIgnoresGuards(t *testing.T) {
+	src := `package a
+
+func testRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+}
+
+func TestSlow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("slow")
+	}
+	// t.Skip("commented out")
+}
+`
+	items, err := NewDetector().DetectInReader("a_test.go", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	items, err = NewDetector().DetectInReader("a.go", strings.NewReader("func TestX(t *testing.T) { t.Skip() }\n"), 3)
+	assert.NoError(t, err)
+	assert.Empty(t, items, "only _test.go files hold Go tests")
+}
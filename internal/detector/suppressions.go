@@ -0,0 +1,114 @@
+package detector
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"tech-debt-collector/internal/models"
+)
+
+// TypeLintSuppression marks a comment or annotation that silences a linter
+// or type checker
+const TypeLintSuppression = "LINT_SUPPRESSION"
+
+// Default severities of lint suppressions. A blanket suppression silences
+// every rule, including ones added later, so it weighs more.
+const (
+	suppressionSeverity        = 2
+	blanketSuppressionSeverity = 4
+)
+
+// suppression recognises one tool's suppression syntax
+type suppression struct {
+	tool      string
+	languages []string
+	inComment bool           // Matched against comment text rather than code lines
+	pattern   *regexp.Regexp // Submatch 1, if present, lists the suppressed rules
+}
+
+var suppressions = []suppression{
+	{"golangci-lint", []string{"go"}, true, regexp.MustCompile(`^\s*nolint\b(?::([\w,-]+))?`)},
+	{"staticcheck", []string{"go"}, true, regexp.MustCompile(`^\s*lint:(?:file-)?ignore\s+(\S+)`)},
+	{"gosec", []string{"go"}, true, regexp.MustCompile(`(?:^|\s)#nosec\b((?:[\s,]+G\d+)*)`)},
+	{"eslint", []string{"javascript", "typescript"}, true, regexp.MustCompile(`\beslint-disable(?:-next-line|-line)?\b(.*?)(?:\s--\s.*)?$`)},
+	{"typescript", []string{"javascript", "typescript"}, true, regexp.MustCompile(`@ts-(?:ignore|nocheck|expect-error)\b`)},
+	{"mypy", []string{"python"}, true, regexp.MustCompile(`\btype:\s*ignore\b(?:\[([^\]]*)\])?`)},
+	{"flake8", []string{"python"}, true, regexp.MustCompile(`(?i)\bnoqa\b(?::\s*([A-Z]+\d+(?:[,\s]+[A-Z]+\d+)*))?`)},
+	{"pylint", []string{"python"}, true, regexp.MustCompile(`\bpylint:\s*disable(?:-next)?=([\w,\s-]+)`)},
+	{"javac", []string{"java", "groovy"}, false, regexp.MustCompile(`^\s*@SuppressWarnings\s*\((.*?)\)`)},
+	{"kotlinc", []string{"kotlin"}, false, regexp.MustCompile(`^\s*@Suppress\s*\((.*?)\)`)},
+	{"rustc", []string{"rust"}, false, regexp.MustCompile(`^\s*#!?\[allow\(([^)]*)\)\]`)},
+	{"clang-tidy", []string{"c", "cpp"}, true, regexp.MustCompile(`\bNOLINT(?:NEXTLINE|BEGIN)?\b(?:\(([^)]*)\))?`)},
+	{"msvc", []string{"c", "cpp"}, false, regexp.MustCompile(`^\s*#\s*pragma\s+warning\s*\(\s*disable\s*:\s*([\d\s]+)\)`)},
+	{"gcc", []string{"c", "cpp"}, false, regexp.MustCompile(`^\s*#\s*pragma\s+(?:GCC|clang)\s+diagnostic\s+ignored\s+"([^"]+)"`)},
+	{"rubocop", []string{"ruby"}, true, regexp.MustCompile(`\brubocop:disable\s+([\w/,\s]+)`)},
+	{"phpcs", []string{"php"}, true, regexp.MustCompile(`\bphpcs:(?:ignore|disable)\b(?:\s+([\w.,\s]+))?`)},
+	{"phpstan", []string{"php"}, true, regexp.MustCompile(`@phpstan-ignore(?:-next-line|-line)?\b`)},
+	{"shellcheck", []string{"shell"}, true, regexp.MustCompile(`\bshellcheck\s+disable=([\w,]+)`)},
+	{"hadolint", []string{"dockerfile"}, true, regexp.MustCompile(`\bhadolint\s+ignore=([\w,]+)`)},
+}
+
+// lintSuppressions reports linter and type checker suppressions, which hide
+// the warnings that would otherwise point at debt
+type lintSuppressions struct{}
+
+func (lintSuppressions) Analyze(src *source) []models.DebtItem {
+	var items []models.DebtItem
+	for _, sup := range suppressions {
+		if !slices.Contains(sup.languages, src.Language) {
+			continue
+		}
+
+		if sup.inComment {
+			for _, cl := range src.Comments {
+				if m := sup.pattern.FindStringSubmatch(cl.Text); m != nil {
+					items = append(items, sup.item(cl.Line, m))
+				}
+			}
+			continue
+		}
+		for i, line := range src.Lines {
+			if m := sup.pattern.FindStringSubmatch(line); m != nil {
+				items = append(items, sup.item(i+1, m))
+			}
+		}
+	}
+	return items
+}
+
+func (sup suppression) item(line int, m []string) models.DebtItem {
+	var rules []string
+	if len(m) > 1 {
+		rules = splitRules(m[1])
+	}
+
+	item := models.DebtItem{
+		LineNumber: line,
+		Type:       TypeLintSuppression,
+		Category:   models.CategoryLint,
+		Rules:      rules,
+		Severity:   suppressionSeverity,
+		Message:    fmt.Sprintf("%s suppression: %s", sup.tool, strings.Join(rules, ", ")),
+	}
+	if len(rules) == 0 || slices.Contains(rules, "all") {
+		item.Severity = blanketSuppressionSeverity
+		item.Message = fmt.Sprintf("blanket %s suppression of all rules", sup.tool)
+	}
+	return item
+}
+
+// splitRules splits a rule list such as `"unchecked", "rawtypes"` or
+// `errcheck,gosec`
+func splitRules(list string) []string {
+	var rules []string
+	for _, f := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '{' || r == '}'
+	}) {
+		if f = strings.Trim(f, `"'`); f != "" {
+			rules = append(rules, f)
+		}
+	}
+	return rules
+}
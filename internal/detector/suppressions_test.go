@@ -0,0 +1,55 @@
+package detector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintSuppressions(t *testing.T) {
+	tests := []struct {
+		path     string
+		line     string
+		rules    []string
+		severity int
+	}{
+		{"a.go", "x() //nolint:errcheck,gosec", []string{"errcheck", "gosec"}, 2},
+		{"a.go", "x() //nolint", nil, 4},
+		{"a.go", "//lint:ignore SA1019 still needed", []string{"SA1019"}, 2},
+		{"a.go", "key := k // #nosec G101", []string{"G101"}, 2},
+		{"a.js", "// eslint-disable-next-line no-console, no-alert -- debugging", []string{"no-console", "no-alert"}, 2},
+		{"a.js", "/* eslint-disable */", nil, 4},
+		{"a.ts", "// @ts-ignore", nil, 4},
+		{"a.py", "x = y  # type: ignore[attr-defined]", []string{"attr-defined"}, 2},
+		{"a.py", "import os  # noqa", nil, 4},
+		{"a.py", "import os  # noqa: F401,E501", []string{"F401", "E501"}, 2},
+		{"a.py", "# pylint: disable=too-many-branches", []string{"too-many-branches"}, 2},
+		{"A.java", `@SuppressWarnings({"unchecked", "rawtypes"})`, []string{"unchecked", "rawtypes"}, 2},
+		{"a.rs", "#[allow(dead_code)]", []string{"dead_code"}, 2},
+		{"a.cpp", "int x; // NOLINT", nil, 4},
+		{"a.c", "#pragma warning(disable: 4996)", []string{"4996"}, 2},
+		{"a.rb", "# rubocop:disable all", []string{"all"}, 4},
+		{"a.sh", "# shellcheck disable=SC2086", []string{"SC2086"}, 2},
+		{"Dockerfile", "# hadolint ignore=DL3008", []string{"DL3008"}, 2},
+	}
+
+	d := NewDetector()
+	for _, tt := range tests {
+		items, err := d.DetectInReader(tt.path, strings.NewReader(tt.line+"\n"), 3)
+		assert.NoError(t, err)
+		if !assert.Len(t, items, 1, tt.line) {
+			continue
+		}
+		assert.Equal(t, TypeLintSuppression, items[0].Type, tt.line)
+		assert.Equal(t, tt.rules, items[0].Rules, tt.line)
+		assert.Equal(t, tt.severity, items[0].Severity, tt.line)
+	}
+}
+
+func TestLintSuppressionsIgnoreStrings(t *testing.T) {
+	src := "msg := \"use //nolint sparingly\"\n"
+	items, err := NewDetector().DetectInReader("a.go", strings.NewReader(src), 3)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+}
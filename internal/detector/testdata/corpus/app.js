@@ -0,0 +1,6 @@
+const api = "http://example.com//TODO";
+const tpl = `
+  // FIXME: inside a template literal
+`;
+const re = '/* HACK */';
+// TODO: real comment
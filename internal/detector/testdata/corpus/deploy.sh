@@ -0,0 +1,6 @@
+#!/bin/sh
+echo "# TODO: not a comment"
+echo 'FIXME: single quoted'
+count=${#todos[@]}
+url=http://example.com/#HACK
+echo done # XXX: trailing comment
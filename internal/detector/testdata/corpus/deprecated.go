@@ -0,0 +1,14 @@
+package corpus
+
+var isDeprecated = map[string]bool{"DEPRECATED": true}
+
+// Deprecated: Use call instead.
+func oldCall() {}
+
+// DEPRECATED remove after v2
+func legacy() {}
+
+/*
+ * DEPRECATED: remove with the v1 routes
+ */
+var deprecatedRoutes = "deprecated" // see undeprecated.go
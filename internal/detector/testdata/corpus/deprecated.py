@@ -0,0 +1,5 @@
+DEPRECATED_NAMES = ["deprecated"]
+
+# deprecated: use the v2 client
+def old_client():
+    return "# DEPRECATED not a comment"
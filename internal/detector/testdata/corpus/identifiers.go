@@ -0,0 +1,17 @@
+package corpus
+
+var todoList = []string{"FIXME", "HACK: not a comment"}
+
+const xxxLimit = 3
+
+// hackathon entries are scored separately; fixmeCount is a counter
+func hackathon(url string) string {
+	return "https://example.com/TODO" + url // TODO: use url.JoinPath
+}
+
+var raw = `
+// HACK: inside a raw string
+`
+
+var n = 1 /* FIXME: block comment */ + 2
+var r = '"' // XXX: after a rune literal
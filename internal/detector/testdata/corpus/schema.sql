@@ -0,0 +1,5 @@
+SELECT '-- TODO: not a comment' FROM todos;
+INSERT INTO fixme_log VALUES ('it''s -- FIXME');
+-- HACK: real comment
+/* XXX: block
+   comment */
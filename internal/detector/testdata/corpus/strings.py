@@ -0,0 +1,12 @@
+def todo_list():
+    """TODO: document the return value"""
+    s = "# FIXME not a comment"
+    t = 'HACK' + "XXX"  # HACK: real comment
+    u = '''
+    FIXME: inside a triple-quoted expression
+    '''
+    return s, t, u
+
+hackathon = r"""
+TODO: docstring-like but assigned
+"""
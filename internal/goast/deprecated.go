@@ -0,0 +1,418 @@
+package goast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tech-debt-collector/internal/models"
+)
+
+// TypeDeprecatedUsage marks a reference to a symbol whose doc comment has a
+// "Deprecated:" paragraph
+const TypeDeprecatedUsage = "DEPRECATED_USAGE"
+
+// deprecatedUsageSeverity is the severity of each reference. The
+// declaration itself is reported by the DEPRECATED marker.
+const deprecatedUsageSeverity = 2
+
+// Deprecations finds references to deprecated symbols across the packages
+// of a repository. Files may be added from several goroutines at once and
+// are resolved together by Usages.
+//
+// There is no type information: an import is resolved to a repository
+// directory through the module paths of the go.mod files added, and a
+// method call to a deprecated method of the same name, unless another
+// method in the repository shares that name. Without a go.mod, an import
+// outside the standard library is matched to the directory sharing the
+// most trailing path elements with it.
+type Deprecations struct {
+	mu      sync.Mutex
+	files   []*fileSummary
+	modules map[string]string // Module path by the directory of its go.mod
+}
+
+// symbol is a deprecated declaration
+type symbol struct {
+	dir, pkg string
+	name     string // Type.Method for methods
+	path     string
+	line     int
+	note     string // The text after "Deprecated:"
+}
+
+// fileSummary is what Usages needs to know about one parsed file
+type fileSummary struct {
+	path, dir, pkg string
+	imports        []importSpec
+	decls          []symbol
+	methods        []string // Names of every method declared in the file
+	refs           []reference
+}
+
+type importSpec struct {
+	name, path string // name is "" unless the import is renamed
+}
+
+// reference is an exported identifier used in a file, qualified by the
+// identifier before the dot, if any
+type reference struct {
+	qualifier string // "" for a bare identifier, "." when not an identifier
+	name      string
+	line      int
+}
+
+// NewDeprecations creates an empty set of files
+func NewDeprecations() *Deprecations {
+	return &Deprecations{}
+}
+
+// Add parses a Go source file and records its deprecated declarations and
+// the exported identifiers it references
+func (d *Deprecations) Add(filename string, src []byte) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return err
+	}
+
+	sum := &fileSummary{
+		path: filename,
+		dir:  path.Dir(filepath.ToSlash(filename)),
+		pkg:  file.Name.Name,
+	}
+	for _, imp := range file.Imports {
+		spec := importSpec{}
+		spec.path, _ = strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			spec.name = imp.Name.Name
+		}
+		sum.imports = append(sum.imports, spec)
+	}
+
+	declared := make(map[*ast.Ident]bool)
+	sum.collectDecls(fset, file, declared)
+	sum.collectRefs(fset, file, declared)
+
+	d.mu.Lock()
+	d.files = append(d.files, sum)
+	d.mu.Unlock()
+	return nil
+}
+
+// AddModule records the module path declared by a go.mod file, so that
+// imports under it resolve to the directories beneath the file. It reports
+// whether the file declares one.
+func (d *Deprecations) AddModule(filename string, src []byte) bool {
+	module := modulePath(src)
+	if module == "" {
+		return false
+	}
+	d.mu.Lock()
+	if d.modules == nil {
+		d.modules = make(map[string]string)
+	}
+	d.modules[path.Dir(filepath.ToSlash(filename))] = module
+	d.mu.Unlock()
+	return true
+}
+
+// Reset forgets the files and modules added so far
+func (d *Deprecations) Reset() {
+	d.mu.Lock()
+	d.files = nil
+	d.modules = nil
+	d.mu.Unlock()
+}
+
+// modulePath returns the path of the module directive of a go.mod file
+func modulePath(src []byte) string {
+	for _, line := range strings.Split(string(src), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+				return unquoted
+			}
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// collectDecls records the file's deprecated declarations and method names,
+// and marks every declaring identifier so it isn't taken for a reference
+func (sum *fileSummary) collectDecls(fset *token.FileSet, file *ast.File, declared map[*ast.Ident]bool) {
+	add := func(id *ast.Ident, name string, docs ...*ast.CommentGroup) {
+		declared[id] = true
+		if !id.IsExported() {
+			return
+		}
+		for _, doc := range docs {
+			if note, ok := deprecationNote(doc); ok {
+				sum.decls = append(sum.decls, symbol{
+					dir:  sum.dir,
+					pkg:  sum.pkg,
+					name: name,
+					path: sum.path,
+					line: fset.Position(id.Pos()).Line,
+					note: note,
+				})
+				return
+			}
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil {
+				sum.methods = append(sum.methods, decl.Name.Name)
+				ast.Inspect(decl.Recv, func(n ast.Node) bool {
+					if id, ok := n.(*ast.Ident); ok {
+						declared[id] = true // A method doesn't use its own receiver type
+					}
+					return true
+				})
+			}
+			add(decl.Name, funcName(decl), decl.Doc)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add(spec.Name, spec.Name.Name, spec.Doc, decl.Doc)
+				case *ast.ValueSpec:
+					for _, id := range spec.Names {
+						add(id, id.Name, spec.Doc, decl.Doc)
+					}
+				}
+			}
+		}
+	}
+}
+
+// collectRefs records the exported identifiers the file uses
+func (sum *fileSummary) collectRefs(fset *token.FileSet, file *ast.File, declared map[*ast.Ident]bool) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.Field:
+			for _, id := range n.Names {
+				declared[id] = true
+			}
+		case *ast.KeyValueExpr:
+			if id, ok := n.Key.(*ast.Ident); ok {
+				declared[id] = true // A struct field name in a composite literal
+			}
+		case *ast.SelectorExpr:
+			declared[n.Sel] = true
+			if !n.Sel.IsExported() {
+				break
+			}
+			qualifier := "."
+			if id, ok := n.X.(*ast.Ident); ok {
+				qualifier = id.Name
+			}
+			sum.refs = append(sum.refs, reference{qualifier, n.Sel.Name, fset.Position(n.Sel.Pos()).Line})
+		case *ast.Ident:
+			if !declared[n] && n.IsExported() {
+				sum.refs = append(sum.refs, reference{"", n.Name, fset.Position(n.Pos()).Line})
+			}
+		}
+		return true
+	})
+}
+
+// deprecationNote returns the text of a "Deprecated:" paragraph in doc
+func deprecationNote(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, para := range strings.Split(doc.Text(), "\n\n") {
+		if note, ok := strings.CutPrefix(strings.TrimSpace(para), "Deprecated:"); ok {
+			return strings.Join(strings.Fields(note), " "), true
+		}
+	}
+	return "", false
+}
+
+// Usages resolves the references of every file added against the
+// deprecated declarations of every file added. Items come out grouped by
+// file, in path order, and carry their file path, line,
+// type, category, message, severity and the symbol they use.
+func (d *Deprecations) Usages() []models.DebtItem {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sort.SliceStable(d.files, func(i, j int) bool { return d.files[i].path < d.files[j].path })
+
+	// Deprecated symbols by directory and name, package names by directory,
+	// and how many times each method name is declared
+	symbols := make(map[string]map[string]symbol)
+	packages := make(map[string]string)
+	methods := make(map[string]int)
+	for _, f := range d.files {
+		if !strings.HasSuffix(f.pkg, "_test") {
+			packages[f.dir] = f.pkg
+		}
+		for _, name := range f.methods {
+			methods[name]++
+		}
+		for _, sym := range f.decls {
+			if symbols[sym.dir] == nil {
+				symbols[sym.dir] = make(map[string]symbol)
+			}
+			symbols[sym.dir][sym.name] = sym
+		}
+	}
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	// Deprecated methods whose name is unique, by method name
+	uniqueMethods := make(map[string]symbol)
+	for _, byName := range symbols {
+		for name, sym := range byName {
+			if _, method, ok := strings.Cut(name, "."); ok && methods[method] == 1 {
+				uniqueMethods[method] = sym
+			}
+		}
+	}
+
+	var items []models.DebtItem
+	for _, f := range d.files {
+		// Packages in this repository imported by the file, by local name
+		imported := make(map[string]string)
+		for _, imp := range f.imports {
+			dir, ok := resolveImport(imp.path, d.modules, packages)
+			if !ok {
+				continue
+			}
+			name := imp.name
+			if name == "" {
+				name = packages[dir]
+			}
+			imported[name] = dir
+		}
+
+		var local map[string]symbol
+		if packages[f.dir] == f.pkg {
+			local = symbols[f.dir]
+		}
+
+		for _, ref := range f.refs {
+			var (
+				sym symbol
+				ok  bool
+			)
+			if dir, isPkg := imported[ref.qualifier]; isPkg {
+				sym, ok = symbols[dir][ref.name]
+			} else if ref.qualifier == "" {
+				sym, ok = local[ref.name]
+			} else {
+				sym, ok = uniqueMethods[ref.name]
+			}
+			if ok {
+				items = append(items, sym.usage(ref.line, f.path))
+			}
+		}
+	}
+	return items
+}
+
+// usage is the item reporting a reference to sym at line of filePath
+func (sym symbol) usage(line int, filePath string) models.DebtItem {
+	qualified := sym.pkg + "." + sym.name
+	message := fmt.Sprintf("use of deprecated %s", qualified)
+	if note, _, _ := strings.Cut(sym.note, ". "); note != "" {
+		message += ": " + strings.TrimSuffix(note, ".")
+	}
+	return models.DebtItem{
+		FilePath:   filePath,
+		LineNumber: line,
+		Type:       TypeDeprecatedUsage,
+		Category:   models.CategoryDeprecation,
+		Message:    message,
+		Severity:   deprecatedUsageSeverity,
+		Symbol:     qualified,
+		DeclaredAt: fmt.Sprintf("%s:%d", sym.path, sym.line),
+	}
+}
+
+// resolveImport finds the repository directory an import path refers to.
+// With modules, that is the directory under the go.mod whose module path is
+// the longest prefix of the import; imports of other modules don't resolve.
+// Without, it is the directory sharing the most trailing path elements with
+// the import, which must not be in the standard library.
+func resolveImport(importPath string, modules, packages map[string]string) (string, bool) {
+	if len(modules) > 0 {
+		best, bestModule := "", ""
+		for modDir, module := range modules {
+			rest, ok := strings.CutPrefix(importPath, module)
+			if !ok || rest != "" && rest[0] != '/' || len(module) <= len(bestModule) {
+				continue
+			}
+			best, bestModule = path.Join(modDir, rest), module
+		}
+		_, ok := packages[best]
+		return best, bestModule != "" && ok
+	}
+
+	elems := strings.Split(importPath, "/")
+	if !strings.Contains(elems[0], ".") {
+		return "", false // The standard library
+	}
+	best, bestLen, tie := "", 0, false
+	for dir := range packages {
+		dirElems := strings.Split(dir, "/")
+		n := 0
+		for n < len(elems) && n < len(dirElems) && elems[len(elems)-1-n] == dirElems[len(dirElems)-1-n] {
+			n++
+		}
+		switch {
+		case n > bestLen:
+			best, bestLen, tie = dir, n, false
+		case n == bestLen && n > 0:
+			tie = true
+		}
+	}
+	return best, bestLen > 0 && !tie
+}
+
+// CountUsages tallies DEPRECATED_USAGE items by symbol, most used first
+func CountUsages(items []models.DebtItem) []models.SymbolUsage {
+	counts := make(map[string]*models.SymbolUsage)
+	var order []*models.SymbolUsage
+	for _, item := range items {
+		if item.Type != TypeDeprecatedUsage {
+			continue
+		}
+		key := item.Symbol + "\x00" + item.DeclaredAt
+		u, ok := counts[key]
+		if !ok {
+			u = &models.SymbolUsage{Symbol: item.Symbol, DeclaredAt: item.DeclaredAt}
+			counts[key] = u
+			order = append(order, u)
+		}
+		u.Usages++
+	}
+
+	usages := make([]models.SymbolUsage, len(order))
+	for i, u := range order {
+		usages[i] = *u
+	}
+	sort.SliceStable(usages, func(i, j int) bool {
+		if usages[i].Usages != usages[j].Usages {
+			return usages[i].Usages > usages[j].Usages
+		}
+		return usages[i].Symbol < usages[j].Symbol
+	})
+	return usages
+}
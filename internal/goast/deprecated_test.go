@@ -0,0 +1,146 @@
+package goast
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/models"
+)
+
+var deprecatedRepo = map[string]string{
+	"repo/internal/store/store.go": `package store
+
+// Open opens the store.
+//
+// Deprecated: Use OpenContext instead. It will be removed in v2.
+func Open() *Store { return nil }
+
+// Deprecated: stores are no longer limited.
+const MaxSize = 10
+
+// Store keeps things
+type Store struct{}
+
+// Get is the old accessor.
+//
+// Deprecated: Use Lookup.
+func (s *Store) Get() {}
+
+func (s *Store) Lookup() {}
+
+func reopen() *Store { return Open() }
+`,
+	"repo/internal/store/store_test.go": `package store_test
+
+import "example.com/repo/internal/store"
+
+func TestOpen() { store.Open() }
+`,
+	"repo/cmd/app/main.go": `package main
+
+import (
+	db "example.com/repo/internal/store"
+	"github.com/other/store/v2"
+)
+
+func main() {
+	s := db.Open()
+	s.Get()
+	_ = db.MaxSize
+	_ = store.Open()
+	var Open = 1
+	_ = Open
+}
+`,
+}
+
+func TestDeprecatedUsages(t *testing.T) {
+	d := NewDeprecations()
+	for name, src := range deprecatedRepo {
+		assert.NoError(t, d.Add(name, []byte(src)))
+	}
+
+	var got []string
+	items := d.Usages()
+	for _, item := range items {
+		got = append(got, fmt.Sprintf("%s:%d %s", item.FilePath, item.LineNumber, item.Symbol))
+	}
+	assert.Equal(t, []string{
+		"repo/cmd/app/main.go:9 store.Open",
+		"repo/cmd/app/main.go:10 store.Store.Get",
+		"repo/cmd/app/main.go:11 store.MaxSize",
+		"repo/internal/store/store.go:21 store.Open",
+		"repo/internal/store/store_test.go:5 store.Open",
+	}, got)
+
+	assert.Equal(t, "use of deprecated store.Open: Use OpenContext instead", items[0].Message)
+	assert.Equal(t, "repo/internal/store/store.go:6", items[0].DeclaredAt)
+	assert.Equal(t, TypeDeprecatedUsage, items[0].Type)
+	assert.Equal(t, models.CategoryDeprecation, items[0].Category)
+
+	assert.Equal(t, []models.SymbolUsage{
+		{Symbol: "store.Open", DeclaredAt: "repo/internal/store/store.go:6", Usages: 3},
+		{Symbol: "store.MaxSize", DeclaredAt: "repo/internal/store/store.go:9", Usages: 1},
+		{Symbol: "store.Store.Get", DeclaredAt: "repo/internal/store/store.go:17", Usages: 1},
+	}, CountUsages(items))
+
+	d.Reset()
+	assert.Empty(t, d.Usages())
+}
+
+func TestDeprecatedMethodsSharingANameAreNotMatched(t *testing.T) {
+	d := NewDeprecations()
+	assert.NoError(t, d.Add("a/a.go", []byte("package a\n\ntype A struct{}\n\n// Deprecated: gone.\nfunc (A) Close() {}\n")))
+	assert.NoError(t, d.Add("b/b.go", []byte("package b\n\ntype B struct{}\n\nfunc (B) Close() {}\n\nfunc f(x B) { x.Close() }\n")))
+	assert.Empty(t, d.Usages())
+}
+
+func TestDeprecationNoteNeedsOwnParagraph(t *testing.T) {
+	d := NewDeprecations()
+	src := "package a\n\n// Old does things. Deprecated: not really.\nfunc Old() {}\n\nfunc f() { Old() }\n"
+	assert.NoError(t, d.Add("a/a.go", []byte(src)))
+	assert.Empty(t, d.Usages())
+}
+
+func TestDeprecatedImportsResolveThroughTheModulePath(t *testing.T) {
+	files := map[string]string{
+		"repo/internal/errors/errors.go": "package errors\n\n// Deprecated: Use Wrap.\nfunc New() {}\n",
+		"repo/a/a.go": `package a
+
+import (
+	"errors"
+	pkgerrors "github.com/pkg/errors"
+	local "example.com/repo/internal/errors"
+)
+
+func f() {
+	errors.New()
+	pkgerrors.New()
+	local.New()
+}
+`,
+	}
+
+	d := NewDeprecations()
+	assert.True(t, d.AddModule("repo/go.mod", []byte("// The app\nmodule example.com/repo // main module\n\ngo 1.21\n")))
+	assert.False(t, d.AddModule("other/go.mod", []byte("go 1.21\n")))
+	for name, src := range files {
+		assert.NoError(t, d.Add(name, []byte(src)))
+	}
+	items := d.Usages()
+	if !assert.Len(t, items, 1) {
+		return
+	}
+	assert.Equal(t, 12, items[0].LineNumber)
+
+	// Without a go.mod, the standard library still never resolves
+	d.Reset()
+	for name, src := range files {
+		assert.NoError(t, d.Add(name, []byte(src)))
+	}
+	for _, item := range d.Usages() {
+		assert.NotEqual(t, 10, item.LineNumber)
+	}
+}
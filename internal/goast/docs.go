@@ -0,0 +1,303 @@
+package goast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"tech-debt-collector/internal/models"
+)
+
+// Documentation rule types, reported by Docs
+const (
+	TypeMissingDoc      = "MISSING_DOC"       // An exported declaration or a package without a doc comment
+	TypeDocNameMismatch = "DOC_NAME_MISMATCH" // A doc comment that doesn't start with the name it documents
+)
+
+// Docs checks the doc comments of the exported API of Go packages: their
+// functions, methods on exported types, types, and the package clause.
+// Files may be added from several goroutines at once. Test files, main
+// packages and testdata are not API and are left out.
+type Docs struct {
+	mu       sync.Mutex
+	files    []*docFile
+	coverage []models.PackageDocs
+
+	severities map[string]int
+	disabled   map[string]bool
+}
+
+// docFile is what Findings needs to know about one parsed file
+type docFile struct {
+	path, dir, pkg string
+	pkgLine        int
+	pkgDoc         string // Text of the package doc comment, if any
+	hasPkgDoc      bool
+	decls          []docDecl
+}
+
+// docDecl is an exported declaration and its doc comment
+type docDecl struct {
+	kind   string // function, method or type
+	name   string // Type.Method for methods
+	ident  string // The name the comment should start with
+	line   int
+	doc    string
+	hasDoc bool
+}
+
+// NewDocs creates an empty set of files, reporting with the severities and
+// disabled rules of a
+func NewDocs(a *Analyzer) *Docs {
+	return &Docs{severities: a.Severities, disabled: a.disabled}
+}
+
+// Add parses a Go source file and records its exported declarations. It
+// reports whether the file is part of a package's API. rel is the file's path
+// relative to the scan root, so that only testdata below the root counts.
+func (d *Docs) Add(filename, rel string, src []byte) bool {
+	slashed := filepath.ToSlash(filename)
+	if strings.HasSuffix(slashed, "_test.go") || hasElem(filepath.ToSlash(rel), "testdata") {
+		return false
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil || file.Name.Name == "main" {
+		return false
+	}
+
+	f := &docFile{
+		path:    filename,
+		dir:     path.Dir(slashed),
+		pkg:     file.Name.Name,
+		pkgLine: fset.Position(file.Package).Line,
+	}
+	f.pkgDoc = file.Doc.Text()
+	f.hasPkgDoc = f.pkgDoc != ""
+
+	add := func(kind, name string, id *ast.Ident, docs ...*ast.CommentGroup) {
+		decl := docDecl{kind: kind, name: name, ident: id.Name, line: fset.Position(id.Pos()).Line}
+		// Text drops directives such as //go:noinline, which don't document
+		for _, doc := range docs {
+			if text := doc.Text(); text != "" {
+				decl.doc, decl.hasDoc = text, true
+				break
+			}
+		}
+		f.decls = append(f.decls, decl)
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() {
+				continue
+			}
+			if decl.Recv == nil {
+				add("function", decl.Name.Name, decl.Name, decl.Doc)
+			} else if recv := receiverType(decl); ast.IsExported(recv) {
+				add("method", recv+"."+decl.Name.Name, decl.Name, decl.Doc)
+			}
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				if !spec.Name.IsExported() {
+					continue
+				}
+				// A lone type may be documented on the declaration; a
+				// type in a group needs its own comment
+				if decl.Lparen.IsValid() {
+					add("type", spec.Name.Name, spec.Name, spec.Doc)
+				} else {
+					add("type", spec.Name.Name, spec.Name, spec.Doc, decl.Doc)
+				}
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.files = append(d.files, f)
+	d.mu.Unlock()
+	return true
+}
+
+// Reset forgets the files added so far. The coverage of the last Findings
+// is kept.
+func (d *Docs) Reset() {
+	d.mu.Lock()
+	d.files = nil
+	d.mu.Unlock()
+}
+
+// Findings reports the undocumented and misdocumented API of the packages
+// added so far, and works out each package's coverage for Coverage
+func (d *Docs) Findings() []models.DebtItem {
+	d.mu.Lock()
+	files := append([]*docFile(nil), d.files...)
+	d.mu.Unlock()
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	// A package is a directory and a package name, which may span files
+	type pkgKey struct{ dir, name string }
+	var order []pkgKey
+	pkgs := make(map[pkgKey][]*docFile)
+	for _, f := range files {
+		key := pkgKey{f.dir, f.pkg}
+		if _, ok := pkgs[key]; !ok {
+			order = append(order, key)
+		}
+		pkgs[key] = append(pkgs[key], f)
+	}
+
+	var items []models.DebtItem
+	var coverage []models.PackageDocs
+	for _, key := range order {
+		pkgFiles := pkgs[key]
+		cov := models.PackageDocs{Package: key.dir, Name: key.name, Exported: 1}
+
+		if doc, ok := packageDoc(pkgFiles); ok {
+			cov.Documented++
+			if !startsWithName(doc.pkgDoc, "Package "+key.name, false) {
+				items = d.add(items, doc.path, doc.pkgLine, TypeDocNameMismatch, key.name,
+					"doc comment of package %s should start with \"Package %s\"", key.name, key.name)
+			}
+		} else {
+			f := docHome(pkgFiles)
+			items = d.add(items, f.path, f.pkgLine, TypeMissingDoc, key.name, "package %s has no doc comment", key.name)
+		}
+
+		for _, f := range pkgFiles {
+			for _, decl := range f.decls {
+				cov.Exported++
+				symbol := key.name + "." + decl.name
+				switch {
+				case !decl.hasDoc:
+					items = d.add(items, f.path, decl.line, TypeMissingDoc, symbol,
+						"exported %s %s has no doc comment", decl.kind, decl.name)
+				case !startsWithName(decl.doc, decl.ident, decl.kind == "type"):
+					cov.Documented++
+					items = d.add(items, f.path, decl.line, TypeDocNameMismatch, symbol,
+						"doc comment of %s %s should start with %q", decl.kind, decl.name, decl.ident)
+				default:
+					cov.Documented++
+				}
+			}
+		}
+		cov.Coverage = math.Round(float64(cov.Documented)*1000/float64(cov.Exported)) / 10
+		coverage = append(coverage, cov)
+	}
+
+	sort.SliceStable(coverage, func(i, j int) bool { return coverage[i].Coverage < coverage[j].Coverage })
+	d.mu.Lock()
+	d.coverage = coverage
+	d.mu.Unlock()
+	return items
+}
+
+// Coverage returns the documentation coverage of each package seen by the
+// last Findings, least documented first
+func (d *Docs) Coverage() []models.PackageDocs {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.coverage
+}
+
+func (d *Docs) add(items []models.DebtItem, filePath string, line int, typ, symbol, format string, args ...any) []models.DebtItem {
+	if d.disabled[typ] {
+		return items
+	}
+	return append(items, models.DebtItem{
+		FilePath:   filePath,
+		LineNumber: line,
+		Type:       typ,
+		Category:   models.CategoryDocs,
+		Message:    fmt.Sprintf(format, args...),
+		Severity:   d.severities[typ],
+		Symbol:     symbol,
+	})
+}
+
+// packageDoc returns the file holding a package's doc comment, preferring
+// doc.go when several files have one
+func packageDoc(files []*docFile) (*docFile, bool) {
+	var found *docFile
+	for _, f := range files {
+		if f.hasPkgDoc && (found == nil || path.Base(filepath.ToSlash(f.path)) == "doc.go") {
+			found = f
+		}
+	}
+	return found, found != nil
+}
+
+// docHome is the file a missing package comment is reported in: the one
+// named after the package if there is one, else the first
+func docHome(files []*docFile) *docFile {
+	for _, f := range files {
+		if path.Base(filepath.ToSlash(f.path)) == f.pkg+".go" {
+			return f
+		}
+	}
+	return files[0]
+}
+
+// startsWithName reports whether a doc comment starts with name as a whole
+// word. Type comments may put an article first, as in "A Scanner walks".
+func startsWithName(doc, name string, article bool) bool {
+	if article {
+		for _, a := range []string{"A ", "An ", "The "} {
+			if strings.HasPrefix(doc, a+name) {
+				doc = doc[len(a):]
+				break
+			}
+		}
+	}
+	rest, ok := strings.CutPrefix(doc, name)
+	if !ok {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// receiverType is the name of a method's receiver type, without pointer or
+// type parameters
+func receiverType(fn *ast.FuncDecl) string {
+	if len(fn.Recv.List) == 0 {
+		return ""
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
+	}
+	if id, ok := recv.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// hasElem reports whether a slash-separated path has an element named elem
+func hasElem(p, elem string) bool {
+	for _, e := range strings.Split(p, "/") {
+		if e == elem {
+			return true
+		}
+	}
+	return false
+}
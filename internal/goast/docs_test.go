@@ -0,0 +1,195 @@
+package goast
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/config"
+	"tech-debt-collector/internal/models"
+)
+
+var docsRepo = map[string]string{
+	"repo/store/doc.go": `// Package store keeps things.
+package store
+`,
+	"repo/store/store.go": `// Storage helpers
+package store
+
+// Open opens the store.
+func Open() *Store { return nil }
+
+// A Store keeps things.
+type Store struct{}
+
+// Get returns a thing.
+func (s *Store) Get() {}
+
+// Fetches a thing.
+func (s *Store) GetAll() {}
+
+func (s *Store) Put() {}
+
+// Opener opens stores.
+func OpenerFor() {}
+
+//go:noinline
+func Close() {}
+
+type (
+	// Key names a thing.
+	Key string
+	Value []byte
+)
+
+// Handle is generic.
+type Handle[T any] struct{}
+
+func (h Handle[T]) Release() {}
+
+type item struct{}
+
+func (item) Exported() {}
+
+func reopen() {}
+`,
+	"repo/store/store_test.go": `package store
+
+func TestOpen() {}
+`,
+	"repo/store/testdata/fixture.go": `package fixture
+
+func Fixture() {}
+`,
+	"repo/cmd/app/main.go": `package main
+
+func Run() {}
+`,
+	"repo/util/util.go": `package util
+
+// Min returns the smaller value.
+func Min(a, b int) int { return a }
+`,
+}
+
+func TestDocs(t *testing.T) {
+	a, _ := NewAnalyzer(config.GoRules{})
+	d := NewDocs(a)
+	added := map[string]bool{}
+	for name, src := range docsRepo {
+		added[name] = d.Add(name, name, []byte(src))
+	}
+	assert.Equal(t, map[string]bool{
+		"repo/store/doc.go":              true,
+		"repo/store/store.go":            true,
+		"repo/store/store_test.go":       false,
+		"repo/store/testdata/fixture.go": false,
+		"repo/cmd/app/main.go":           false,
+		"repo/util/util.go":              true,
+	}, added)
+
+	var got []string
+	items := d.Findings()
+	for _, item := range items {
+		got = append(got, fmt.Sprintf("%s:%d %s %s: %s", item.FilePath, item.LineNumber, item.Type, item.Symbol, item.Message))
+	}
+	assert.Equal(t, []string{
+		`repo/store/store.go:14 DOC_NAME_MISMATCH store.Store.GetAll: doc comment of method Store.GetAll should start with "GetAll"`,
+		`repo/store/store.go:16 MISSING_DOC store.Store.Put: exported method Store.Put has no doc comment`,
+		`repo/store/store.go:19 DOC_NAME_MISMATCH store.OpenerFor: doc comment of function OpenerFor should start with "OpenerFor"`,
+		`repo/store/store.go:22 MISSING_DOC store.Close: exported function Close has no doc comment`,
+		`repo/store/store.go:27 MISSING_DOC store.Value: exported type Value has no doc comment`,
+		`repo/store/store.go:33 MISSING_DOC store.Handle.Release: exported method Handle.Release has no doc comment`,
+		`repo/util/util.go:1 MISSING_DOC util: package util has no doc comment`,
+	}, got)
+
+	for _, item := range items {
+		assert.Equal(t, models.CategoryDocs, item.Category)
+		assert.Equal(t, 1, item.Severity)
+	}
+
+	assert.Equal(t, []models.PackageDocs{
+		{Package: "repo/util", Name: "util", Exported: 2, Documented: 1, Coverage: 50},
+		{Package: "repo/store", Name: "store", Exported: 12, Documented: 8, Coverage: 66.7},
+	}, d.Coverage())
+
+	d.Reset()
+	assert.Empty(t, d.Findings())
+	assert.Empty(t, d.Coverage())
+}
+
+func TestDocsPackageComment(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			"missing, reported in the file named after the package",
+			map[string]string{"p/a.go": "package p\n", "p/p.go": "package p\n"},
+			[]string{"p/p.go:1 MISSING_DOC"},
+		},
+		{
+			"wrong start",
+			map[string]string{"p/a.go": "\n// This package does things.\npackage p\n"},
+			[]string{"p/a.go:3 DOC_NAME_MISMATCH"},
+		},
+		{
+			"name is a prefix of another",
+			map[string]string{"p/p.go": "// Package pq does things.\npackage p\n"},
+			[]string{"p/p.go:2 DOC_NAME_MISMATCH"},
+		},
+		{
+			"doc.go is preferred",
+			map[string]string{"p/a.go": "// Helpers\npackage p\n", "p/doc.go": "// Package p does things.\npackage p\n"},
+			nil,
+		},
+		{
+			"directives are not documentation",
+			map[string]string{"p/p.go": "//go:build linux\n\npackage p\n"},
+			[]string{"p/p.go:3 MISSING_DOC"},
+		},
+		{
+			"same directory, different packages",
+			map[string]string{"p/p.go": "// Package p does things.\npackage p\n", "p/q.go": "package q\n"},
+			[]string{"p/q.go:1 MISSING_DOC"},
+		},
+	}
+	a, _ := NewAnalyzer(config.GoRules{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDocs(a)
+			for name, src := range tt.files {
+				d.Add(name, name, []byte(src))
+			}
+			var got []string
+			for _, item := range d.Findings() {
+				got = append(got, fmt.Sprintf("%s:%d %s", item.FilePath, item.LineNumber, item.Type))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDocsRules(t *testing.T) {
+	src := []byte("package p\n\n// Does things.\nfunc Do() {}\n\nfunc Undone() {}\n")
+	a, err := NewAnalyzer(config.GoRules{
+		Severities: map[string]int{TypeMissingDoc: 2},
+		Disabled:   []string{TypeDocNameMismatch},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	d := NewDocs(a)
+	d.Add("p/p.go", "p/p.go", src)
+	items := d.Findings()
+	if !assert.Len(t, items, 2) {
+		return
+	}
+	assert.Equal(t, "p", items[0].Symbol)
+	assert.Equal(t, "p.Undone", items[1].Symbol)
+	assert.Equal(t, 2, items[1].Severity)
+	// A mismatched comment still counts as documented
+	assert.Equal(t, 33.3, d.Coverage()[0].Coverage)
+}
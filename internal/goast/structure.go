@@ -0,0 +1,399 @@
+package goast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tech-debt-collector/internal/config"
+	"tech-debt-collector/internal/models"
+)
+
+// Rule types reported by the structure analyzer
+const (
+	TypeLongFunction     = "LONG_FUNCTION"
+	TypeComplexFunction  = "COMPLEX_FUNCTION"
+	TypeDeepNesting      = "DEEP_NESTING"
+	TypeNotImplemented   = "NOT_IMPLEMENTED"
+	TypeIgnoredError     = "IGNORED_ERROR"
+	TypeEmptyErrorBranch = "EMPTY_ERROR_BRANCH"
+)
+
+// DefaultSeverities is the severity of each rule unless configured
+var DefaultSeverities = map[string]int{
+	TypeLongFunction:     2,
+	TypeComplexFunction:  3,
+	TypeDeepNesting:      2,
+	TypeNotImplemented:   4,
+	TypeIgnoredError:     3,
+	TypeEmptyErrorBranch: 4,
+	TypeMissingDoc:       1,
+	TypeDocNameMismatch:  1,
+}
+
+// Default thresholds
+const (
+	DefaultMaxFunctionLines = 80
+	DefaultMaxComplexity    = 15
+	DefaultMaxNesting       = 4
+)
+
+// stdlibErrorFuncs are standard library functions, by import path and name,
+// whose last result is an error. Without type information they, errorMethods
+// and the functions declared in the same file are the only calls known to
+// return one; functions of other packages are not checked.
+var stdlibErrorFuncs = map[string]bool{
+	"os.Remove": true, "os.RemoveAll": true, "os.Rename": true, "os.Mkdir": true,
+	"os.MkdirAll": true, "os.Setenv": true, "os.Unsetenv": true, "os.ReadFile": true,
+	"os.WriteFile": true, "io.ReadAll": true, "io.Copy": true, "io.WriteString": true,
+	"encoding/json.Marshal": true, "encoding/json.Unmarshal": true, "strconv.Atoi": true,
+	"strconv.ParseInt": true, "strconv.ParseUint": true, "strconv.ParseFloat": true,
+	"strconv.ParseBool": true,
+}
+
+// errorMethods are methods of standard library types, such as *os.File,
+// *bufio.Writer and *http.Server, whose last result is an error. The receiver
+// isn't known, so they match by name.
+var errorMethods = map[string]bool{
+	"Close": true, "Flush": true, "Sync": true, "Write": true, "WriteString": true,
+	"WriteTo": true, "Encode": true, "Decode": true, "Shutdown": true,
+}
+
+// notImplemented are panic messages that mark a stub
+var notImplemented = map[string]bool{
+	"not implemented": true, "not yet implemented": true, "unimplemented": true, "todo": true,
+}
+
+// Analyzer reports structural debt in Go source files
+type Analyzer struct {
+	MaxFunctionLines int
+	MaxComplexity    int
+	MaxNesting       int
+	BlankErrors      bool // Also report `_ = f()`, which usually discards an error on purpose
+	Severities       map[string]int
+	disabled         map[string]bool
+}
+
+// NewAnalyzer creates an analyzer with the default thresholds and
+// severities, overridden by rules
+func NewAnalyzer(rules config.GoRules) (*Analyzer, error) {
+	a := &Analyzer{
+		MaxFunctionLines: DefaultMaxFunctionLines,
+		MaxComplexity:    DefaultMaxComplexity,
+		MaxNesting:       DefaultMaxNesting,
+		BlankErrors:      rules.BlankErrors,
+		Severities:       make(map[string]int),
+		disabled:         make(map[string]bool),
+	}
+	for typ, sev := range DefaultSeverities {
+		a.Severities[typ] = sev
+	}
+
+	if rules.MaxFunctionLines > 0 {
+		a.MaxFunctionLines = rules.MaxFunctionLines
+	}
+	if rules.MaxComplexity > 0 {
+		a.MaxComplexity = rules.MaxComplexity
+	}
+	if rules.MaxNesting > 0 {
+		a.MaxNesting = rules.MaxNesting
+	}
+	for typ, sev := range rules.Severities {
+		if _, ok := DefaultSeverities[typ]; !ok {
+			return nil, fmt.Errorf("go rules: unknown rule %q", typ)
+		}
+		a.Severities[typ] = sev
+	}
+	for _, typ := range rules.Disabled {
+		if _, ok := DefaultSeverities[typ]; !ok {
+			return nil, fmt.Errorf("go rules: unknown rule %q", typ)
+		}
+		a.disabled[typ] = true
+	}
+
+	return a, nil
+}
+
+// AnalyzeFile parses Go source and reports its structural debt. Items carry
+// their lines, type, category, message and severity.
+func (a *Analyzer) AnalyzeFile(filename string, src []byte) ([]models.DebtItem, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &visitor{a: a, fset: fset, errFuncs: errorFuncs(file), imports: importNames(file)}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			v.function(fn)
+		}
+	}
+
+	sort.SliceStable(v.items, func(i, j int) bool { return v.items[i].LineNumber < v.items[j].LineNumber })
+	return v.items, nil
+}
+
+type visitor struct {
+	a        *Analyzer
+	fset     *token.FileSet
+	errFuncs map[string]bool
+	imports  map[string]string // Import path by package name
+	items    []models.DebtItem
+}
+
+func (v *visitor) report(typ string, start, end token.Pos, format string, args ...interface{}) {
+	if v.a.disabled[typ] {
+		return
+	}
+	v.items = append(v.items, models.DebtItem{
+		LineNumber: v.fset.Position(start).Line,
+		EndLine:    v.fset.Position(end).Line,
+		Type:       typ,
+		Category:   models.CategoryStructure,
+		Message:    fmt.Sprintf(format, args...),
+		Severity:   v.a.Severities[typ],
+	})
+}
+
+// function applies every rule to one function declaration
+func (v *visitor) function(fn *ast.FuncDecl) {
+	name := funcName(fn)
+
+	lines := v.fset.Position(fn.Body.Rbrace).Line - v.fset.Position(fn.Body.Lbrace).Line - 1
+	if lines > v.a.MaxFunctionLines {
+		v.report(TypeLongFunction, fn.Pos(), fn.End(), "function %s is %d lines long (max %d)", name, lines, v.a.MaxFunctionLines)
+	}
+
+	if c := complexity(fn.Body); c > v.a.MaxComplexity {
+		v.report(TypeComplexFunction, fn.Pos(), fn.End(), "function %s has cyclomatic complexity %d (max %d)", name, c, v.a.MaxComplexity)
+	}
+
+	if depth, deepest := nesting(fn.Body); depth > v.a.MaxNesting {
+		v.report(TypeDeepNesting, deepest.Pos(), deepest.Pos(), "function %s nests control flow %d levels deep (max %d)", name, depth, v.a.MaxNesting)
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if msg, ok := panicMessage(n); ok && notImplemented[msg] {
+				v.report(TypeNotImplemented, n.Pos(), n.End(), "function %s panics with %q", name, msg)
+			}
+		case *ast.AssignStmt:
+			if callee, ok := v.discardedError(n); ok {
+				v.report(TypeIgnoredError, n.Pos(), n.End(), "error from %s is discarded", callee)
+			}
+		case *ast.IfStmt:
+			if isErrCheck(n.Cond) && len(n.Body.List) == 0 {
+				v.report(TypeEmptyErrorBranch, n.Pos(), n.End(), "error branch in %s is empty", name)
+			}
+		}
+		return true
+	})
+}
+
+// complexity is the cyclomatic complexity of a function body: one plus
+// the number of decision points
+func complexity(body *ast.BlockStmt) int {
+	c := 1
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			c++
+		case *ast.CaseClause:
+			if n.List != nil {
+				c++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				c++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				c++
+			}
+		}
+		return true
+	})
+	return c
+}
+
+// nesting returns the deepest level of nested control flow in body and the
+// statement found there. An else-if continues its chain rather than nesting.
+func nesting(body *ast.BlockStmt) (int, ast.Node) {
+	var (
+		max, depth int
+		deepest    ast.Node
+		stack      []bool // Whether each open node added a level
+		elseIf     = make(map[ast.Node]bool)
+	)
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			if stack[len(stack)-1] {
+				depth--
+			}
+			stack = stack[:len(stack)-1]
+			return true
+		}
+
+		level := false
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			if next, ok := n.Else.(*ast.IfStmt); ok {
+				elseIf[next] = true
+			}
+			level = !elseIf[n]
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			level = true
+		}
+		if level {
+			depth++
+			if depth > max {
+				max, deepest = depth, n
+			}
+		}
+		stack = append(stack, level)
+		return true
+	})
+	return max, deepest
+}
+
+// panicMessage returns the lower-cased string literal passed to panic
+func panicMessage(call *ast.CallExpr) (string, bool) {
+	if id, ok := call.Fun.(*ast.Ident); !ok || id.Name != "panic" || len(call.Args) != 1 {
+		return "", false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	msg, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", false
+	}
+	return strings.Trim(strings.ToLower(strings.TrimSpace(msg)), ".!"), true
+}
+
+// discardedError recognises `v, _ := f()` where f is known to return an
+// error last. Assignments to blanks only, such as `_, _ = w.Write(p)`, are
+// deliberate and only recognised with BlankErrors.
+func (v *visitor) discardedError(as *ast.AssignStmt) (string, bool) {
+	if len(as.Rhs) != 1 || !isBlank(as.Lhs[len(as.Lhs)-1]) {
+		return "", false
+	}
+	if !v.a.BlankErrors && allBlank(as.Lhs) {
+		return "", false
+	}
+	call, ok := as.Rhs[0].(*ast.CallExpr)
+	if !ok || !v.returnsError(call.Fun) {
+		return "", false
+	}
+	return exprString(call.Fun), true
+}
+
+// returnsError reports whether the function called is known to return an
+// error last
+func (v *visitor) returnsError(fun ast.Expr) bool {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return v.errFuncs[f.Name]
+	case *ast.SelectorExpr:
+		if pkg, ok := f.X.(*ast.Ident); ok {
+			if imp, ok := v.imports[pkg.Name]; ok {
+				return stdlibErrorFuncs[imp+"."+f.Sel.Name]
+			}
+		}
+		return v.errFuncs[f.Sel.Name] || errorMethods[f.Sel.Name]
+	}
+	return false
+}
+
+// importNames maps the names file refers to its imports by to their paths
+func importNames(file *ast.File) map[string]string {
+	names := make(map[string]string)
+	for _, imp := range file.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := path.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		names[name] = p
+	}
+	return names
+}
+
+// errorFuncs lists the functions and methods declared in file whose last
+// result is an error
+func errorFuncs(file *ast.File) map[string]bool {
+	funcs := make(map[string]bool)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Type.Results == nil || len(fn.Type.Results.List) == 0 {
+			continue
+		}
+		results := fn.Type.Results.List
+		if id, ok := results[len(results)-1].Type.(*ast.Ident); ok && id.Name == "error" {
+			funcs[fn.Name.Name] = true
+		}
+	}
+	return funcs
+}
+
+// isErrCheck recognises `err != nil`, including names such as closeErr
+func isErrCheck(cond ast.Expr) bool {
+	bin, ok := cond.(*ast.BinaryExpr)
+	if !ok || bin.Op != token.NEQ {
+		return false
+	}
+	x, ok := bin.X.(*ast.Ident)
+	y, isNil := bin.Y.(*ast.Ident)
+	return ok && isNil && y.Name == "nil" && (x.Name == "err" || strings.HasSuffix(x.Name, "Err"))
+}
+
+func isBlank(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "_"
+}
+
+func allBlank(exprs []ast.Expr) bool {
+	for _, e := range exprs {
+		if !isBlank(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// exprString renders simple selector chains such as f.Close
+func exprString(e ast.Expr) string {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.CallExpr:
+		return exprString(e.Fun) + "()"
+	}
+	return "call"
+}
+
+// funcName is a function's name, qualified by its receiver type for methods
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if idx, ok := recv.(*ast.IndexExpr); ok {
+		recv = idx.X
+	}
+	return exprString(recv) + "." + fn.Name.Name
+}
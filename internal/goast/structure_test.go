@@ -0,0 +1,146 @@
+package goast
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tech-debt-collector/internal/config"
+)
+
+func analyze(t *testing.T, rules config.GoRules, src string) []string {
+	t.Helper()
+	a, err := NewAnalyzer(rules)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	items, err := a.AnalyzeFile("a.go", []byte(src))
+	assert.NoError(t, err)
+
+	var got []string
+	for _, item := range items {
+		got = append(got, fmt.Sprintf("%d %s %d", item.LineNumber, item.Type, item.Severity))
+	}
+	return got
+}
+
+func TestAnalyzeFile(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			"empty result list",
+			"package a\n\nfunc f() () {}\n",
+			nil,
+		},
+		{
+			"clean",
+			"package a\n\nfunc f(x int) int {\n\tif x > 0 {\n\t\treturn x\n\t}\n\treturn 0\n}\n",
+			nil,
+		},
+		{
+			"not implemented",
+			"package a\n\nfunc f() {\n\tpanic(\"Not implemented.\")\n}\n\nfunc g() {\n\tpanic(\"unreachable\")\n}\n",
+			[]string{"4 NOT_IMPLEMENTED 4"},
+		},
+		{
+			"ignored errors",
+			"package a\n\nimport \"os\"\n\nfunc load() (int, error) { return 0, nil }\n\nfunc f(file *os.File) {\n\t_ = file.Close()\n\tn, _ := load()\n\t_, _ = n, 1\n}\n",
+			[]string{"9 IGNORED_ERROR 3"},
+		},
+		{
+			"empty error branch",
+			"package a\n\nfunc f() {\n\terr := g()\n\tif err != nil {\n\t}\n\tif closeErr := g(); closeErr != nil {\n\t\treturn\n\t}\n}\n",
+			[]string{"5 EMPTY_ERROR_BRANCH 4"},
+		},
+		{
+			"deep nesting",
+			"package a\n\nfunc f(xs [][]int) {\n\tfor _, x := range xs {\n\t\tfor _, y := range x {\n\t\t\tif y > 0 {\n\t\t\t\tswitch y {\n\t\t\t\tcase 1:\n\t\t\t\t\tif y == 1 {\n\t\t\t\t\t\tprintln(y)\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t}\n\t\t}\n\t}\n}\n",
+			[]string{"9 DEEP_NESTING 2"},
+		},
+		{
+			"else if chain is not nesting",
+			"package a\n\nfunc f(x int) {\n\tfor {\n\t\tif x == 1 {\n\t\t} else if x == 2 {\n\t\t} else if x == 3 {\n\t\t} else if x == 4 {\n\t\t} else if x == 5 {\n\t\t}\n\t}\n}\n",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, analyze(t, config.GoRules{}, tt.src))
+		})
+	}
+}
+
+func TestAnalyzeFileBlankErrors(t *testing.T) {
+	src := `package a
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+)
+
+func load() (int, error) { return 0, nil }
+
+func f(w http.ResponseWriter, file *os.File, cache *Cache) {
+	_ = file.Close()
+	n, _ := load()
+	data, _ := json.Marshal(n)
+	_, _ = w.Write(data)
+	_ = os.Remove("tmp")
+	old, _ := cache.Remove("k")
+	_, _ = old, data
+}
+`
+	assert.Equal(t, []string{"13 IGNORED_ERROR 3", "14 IGNORED_ERROR 3"}, analyze(t, config.GoRules{}, src),
+		"errors discarded on purpose are left alone")
+	assert.Equal(t, []string{"12 IGNORED_ERROR 3", "13 IGNORED_ERROR 3", "14 IGNORED_ERROR 3", "15 IGNORED_ERROR 3", "16 IGNORED_ERROR 3"},
+		analyze(t, config.GoRules{BlankErrors: true}, src))
+}
+
+func TestAnalyzeFileLongAndComplex(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("package a\n\nfunc (s *Server) handle(x int) {\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&b, "\tif x == %d && x > 0 {\n\t\tprintln(x)\n\t}\n", i)
+	}
+	b.WriteString("}\n")
+	src := b.String()
+
+	assert.Nil(t, analyze(t, config.GoRules{MaxComplexity: 50}, src))
+	assert.Equal(t, []string{"3 LONG_FUNCTION 2", "3 COMPLEX_FUNCTION 3"}, analyze(t, config.GoRules{MaxFunctionLines: 59}, src))
+	assert.Equal(t, []string{"3 COMPLEX_FUNCTION 5"}, analyze(t, config.GoRules{
+		Severities: map[string]int{TypeComplexFunction: 5},
+	}, src))
+	assert.Nil(t, analyze(t, config.GoRules{Disabled: []string{TypeComplexFunction}}, src))
+
+	a, err := NewAnalyzer(config.GoRules{})
+	assert.NoError(t, err)
+	items, err := a.AnalyzeFile("a.go", []byte(src))
+	assert.NoError(t, err)
+	if !assert.Len(t, items, 1) {
+		return
+	}
+	assert.Equal(t, 64, items[0].EndLine)
+	assert.Equal(t, "function Server.handle has cyclomatic complexity 41 (max 15)", items[0].Message)
+}
+
+func TestNewAnalyzerRejectsUnknownRules(t *testing.T) {
+	_, err := NewAnalyzer(config.GoRules{Disabled: []string{"LONG_FUNCTIONS"}})
+	assert.EqualError(t, err, `go rules: unknown rule "LONG_FUNCTIONS"`)
+
+	_, err = NewAnalyzer(config.GoRules{Severities: map[string]int{"NESTING": 1}})
+	assert.Error(t, err)
+}
+
+func TestAnalyzeFileSyntaxError(t *testing.T) {
+	a, err := NewAnalyzer(config.GoRules{})
+	assert.NoError(t, err)
+	_, err = a.AnalyzeFile("a.go", []byte("package a\nfunc {"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package iac
+
+import (
+	"strings"
+
+	"tech-debt-collector/internal/models"
+)
+
+// analyzeCompose checks the image, user, environment and healthcheck of
+// each service of a compose file
+func analyzeCompose(doc *node) []models.DebtItem {
+	var items []models.DebtItem
+	for _, svc := range doc.get("services").pairs {
+		s := svc.value
+		if image := s.get("image"); image != nil {
+			if item, ok := imageItem(image.str(), image.line); ok {
+				items = append(items, item)
+			}
+		}
+
+		if user := s.get("user"); user != nil && isRootUser(user.str()) {
+			items = append(items, newItem(user.line, TypeRootUser, "service %s runs as user %s", svc.key, user.str()))
+		}
+		if p := s.get("privileged"); p.str() == "true" {
+			items = append(items, newItem(p.line, TypeRootUser, "service %s runs privileged", svc.key))
+		}
+
+		for _, env := range composeEnvironment(s.get("environment")) {
+			if item, ok := credentialItem(env.key, env.value.str(), env.line, "service "+svc.key); ok {
+				items = append(items, item)
+			}
+		}
+
+		if hc := s.get("healthcheck"); hc == nil || hc.get("disable").str() == "true" {
+			items = append(items, newItem(svc.line, TypeMissingHealthcheck, "service %s has no healthcheck", svc.key))
+		}
+	}
+	return items
+}
+
+// composeEnvironment returns the variables of an environment block, given
+// either as a mapping or as a list of KEY=value entries
+func composeEnvironment(env *node) []pair {
+	if env == nil {
+		return nil
+	}
+	if !env.isSeq {
+		return env.pairs
+	}
+	var vars []pair
+	for _, item := range env.items {
+		key, value, _ := strings.Cut(item.str(), "=")
+		vars = append(vars, pair{key: key, line: item.line, value: &node{line: item.line, scalar: value}})
+	}
+	return vars
+}
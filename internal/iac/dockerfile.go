@@ -0,0 +1,162 @@
+package iac
+
+import (
+	"strings"
+
+	"tech-debt-collector/internal/models"
+)
+
+// instruction is one Dockerfile instruction, its continuation lines joined
+type instruction struct {
+	cmd  string // Upper-cased, e.g. FROM
+	args string
+	line int
+}
+
+// analyzeDockerfile checks every FROM image, ENV and ARG values, and
+// whether the final stage drops root and declares a HEALTHCHECK
+func analyzeDockerfile(lines []string) []models.DebtItem {
+	// What a stage sets is inherited by stages built FROM it
+	type stageState struct {
+		user        string
+		healthcheck bool
+	}
+	var items []models.DebtItem
+	stages := make(map[string]stageState) // Earlier build stages, by name
+	stage := ""
+	final := 0 // Line of the last FROM
+	user, healthcheck := "", false
+
+	for _, ins := range dockerInstructions(lines) {
+		switch ins.cmd {
+		case "FROM":
+			if stage != "" {
+				stages[stage] = stageState{user, healthcheck}
+			}
+			var image string
+			image, stage = parseFrom(ins.args)
+			stage = strings.ToLower(stage)
+			user, healthcheck = "", false
+			if base, ok := stages[strings.ToLower(image)]; ok {
+				user, healthcheck = base.user, base.healthcheck
+			} else if item, ok := imageItem(image, ins.line); ok {
+				items = append(items, item)
+			}
+			final = ins.line
+		case "USER":
+			user = strings.TrimSpace(ins.args)
+		case "HEALTHCHECK":
+			healthcheck = !strings.EqualFold(strings.TrimSpace(ins.args), "NONE")
+		case "ENV", "ARG":
+			for _, kv := range envPairs(ins.args) {
+				if item, ok := credentialItem(kv[0], kv[1], ins.line, ins.cmd); ok {
+					items = append(items, item)
+				}
+			}
+		}
+	}
+
+	if final == 0 {
+		return items
+	}
+	switch {
+	case user == "":
+		items = append(items, newItem(final, TypeRootUser, "the final stage has no USER, so the container runs as root"))
+	case isRootUser(user):
+		items = append(items, newItem(final, TypeRootUser, "the final stage runs as USER %s", user))
+	}
+	if !healthcheck {
+		items = append(items, newItem(final, TypeMissingHealthcheck, "the final stage has no HEALTHCHECK"))
+	}
+	return items
+}
+
+// dockerInstructions splits a Dockerfile into instructions, joining lines
+// continued with a backslash and skipping comments
+func dockerInstructions(lines []string) []instruction {
+	var out []instruction
+	for i := 0; i < len(lines); i++ {
+		text := strings.TrimSpace(lines[i])
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		start := i + 1
+		for strings.HasSuffix(text, "\\") && i+1 < len(lines) {
+			i++
+			next := strings.TrimSpace(lines[i])
+			if strings.HasPrefix(next, "#") {
+				continue
+			}
+			text = strings.TrimSuffix(text, "\\") + " " + next
+		}
+		cmd, args, _ := strings.Cut(text, " ")
+		out = append(out, instruction{cmd: strings.ToUpper(cmd), args: strings.TrimSpace(args), line: start})
+	}
+	return out
+}
+
+// parseFrom returns the image and stage name of FROM [--platform=...] image [AS name]
+func parseFrom(args string) (string, string) {
+	fields := strings.Fields(args)
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return "", ""
+	}
+	if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+		return fields[0], fields[2]
+	}
+	return fields[0], ""
+}
+
+// envPairs splits the arguments of ENV or ARG into names and values, in
+// both the KEY=value and the legacy "KEY value" forms
+func envPairs(args string) [][2]string {
+	if !strings.Contains(strings.SplitN(args, " ", 2)[0], "=") {
+		name, value, _ := strings.Cut(args, " ")
+		if strings.TrimSpace(name) == "" {
+			return nil
+		}
+		return [][2]string{{name, value}}
+	}
+
+	var pairs [][2]string
+	for _, field := range splitQuoted(args) {
+		name, value, ok := strings.Cut(field, "=")
+		if ok {
+			pairs = append(pairs, [2]string{name, value})
+		}
+	}
+	return pairs
+}
+
+// splitQuoted splits on spaces outside double or single quotes
+func splitQuoted(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			cur.WriteRune(r)
+		case r == ' ' || r == '\t':
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
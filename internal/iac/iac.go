@@ -0,0 +1,123 @@
+// Package iac finds debt in infrastructure as code: Dockerfiles, docker
+// compose files and Kubernetes manifests. Each rule looks at one file.
+package iac
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"tech-debt-collector/internal/models"
+	"tech-debt-collector/internal/secrets"
+)
+
+// Types of infrastructure debt
+const (
+	TypeUnpinnedImage       = "IAC_UNPINNED_IMAGE"       // An image without a tag, or tagged latest
+	TypeRootUser            = "IAC_ROOT_USER"            // A container running as root or privileged
+	TypeHardcodedCredential = "IAC_HARDCODED_CREDENTIAL" // A password or key written into the file
+	TypeMissingHealthcheck  = "IAC_MISSING_HEALTHCHECK"  // Nothing tells the orchestrator the service is healthy
+)
+
+// severities of each type
+var severities = map[string]int{
+	TypeUnpinnedImage:       3,
+	TypeRootUser:            3,
+	TypeHardcodedCredential: 4,
+	TypeMissingHealthcheck:  2,
+}
+
+// Analyze checks a Dockerfile, or a YAML file holding compose services or
+// Kubernetes objects. Other files yield nothing.
+func Analyze(filePath, language string, lines []string) []models.DebtItem {
+	switch language {
+	case "dockerfile":
+		return analyzeDockerfile(lines)
+	case "yaml":
+		var items []models.DebtItem
+		for _, doc := range parseYAML(lines) {
+			switch {
+			case isCompose(filePath, doc):
+				items = append(items, analyzeCompose(doc)...)
+			case doc.get("apiVersion") != nil && doc.get("kind") != nil:
+				items = append(items, analyzeKubernetes(doc)...)
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+// isCompose recognises a compose file by its services, at least one of
+// which has an image or a build
+func isCompose(filePath string, doc *node) bool {
+	services := doc.get("services")
+	if services == nil || len(services.pairs) == 0 {
+		return false
+	}
+	base := path.Base(filepath.ToSlash(filePath))
+	if strings.Contains(base, "compose") {
+		return true
+	}
+	for _, s := range services.pairs {
+		if s.value.get("image") != nil || s.value.get("build") != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func newItem(line int, typ, format string, args ...any) models.DebtItem {
+	return models.DebtItem{
+		LineNumber: line,
+		Type:       typ,
+		Category:   models.CategoryInfrastructure,
+		Message:    fmt.Sprintf(format, args...),
+		Severity:   severities[typ],
+	}
+}
+
+// imageItem reports an image reference that isn't pinned to a tag or
+// digest. Images built from variables can't be judged.
+func imageItem(image string, line int) (models.DebtItem, bool) {
+	if image == "" || image == "scratch" || strings.Contains(image, "$") || strings.Contains(image, "{{") || strings.Contains(image, "@") {
+		return models.DebtItem{}, false
+	}
+	name := image[strings.LastIndexByte(image, '/')+1:]
+	_, tag, tagged := strings.Cut(name, ":")
+	switch {
+	case !tagged:
+		return newItem(line, TypeUnpinnedImage, "image %s has no tag, so it pulls latest", image), true
+	case tag == "latest":
+		return newItem(line, TypeUnpinnedImage, "image %s is not pinned to a version", image), true
+	}
+	return models.DebtItem{}, false
+}
+
+// credentialItem reports a variable holding a literal secret. The value
+// itself is never put in the message.
+func credentialItem(name, value string, line int, where string) (models.DebtItem, bool) {
+	value = strings.TrimSpace(value)
+	if !secrets.IsCredentialName(name) || !isLiteral(value) {
+		return models.DebtItem{}, false
+	}
+	return newItem(line, TypeHardcodedCredential, "%s sets %s to a hard-coded value", where, name), true
+}
+
+// isLiteral reports whether a variable's value is written out, rather than
+// empty, a flag, or substituted from elsewhere
+func isLiteral(value string) bool {
+	switch strings.ToLower(strings.Trim(value, `"'`)) {
+	case "", "true", "false", "yes", "no", "on", "off", "null", "~":
+		return false
+	}
+	return !strings.Contains(value, "$") && !strings.Contains(value, "{{")
+}
+
+// isRootUser reports whether a user spec names root, e.g. "root", "0" or
+// "0:0"
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(user), ":")
+	return name == "root" || name == "0"
+}
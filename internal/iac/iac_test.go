@@ -0,0 +1,184 @@
+package iac
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func splitTestLines(src string) []string {
+	return strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+}
+
+// findings lists the items for a file as "line type message"
+func findings(path, language, src string) []string {
+	var got []string
+	for _, item := range Analyze(path, language, splitTestLines(src)) {
+		got = append(got, fmt.Sprintf("%d %s %s", item.LineNumber, item.Type, item.Message))
+	}
+	return got
+}
+
+func TestDockerfile(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			"multi-stage",
+			"FROM golang:1.21 AS build\nRUN go build\n\nFROM alpine:latest\nENV DB_PASSWORD=hunter2 \\\n    DB_HOST=db\nARG API_TOKEN\nCOPY --from=build /app /app\n",
+			[]string{
+				"4 IAC_UNPINNED_IMAGE image alpine:latest is not pinned to a version",
+				"5 IAC_HARDCODED_CREDENTIAL ENV sets DB_PASSWORD to a hard-coded value",
+				"4 IAC_ROOT_USER the final stage has no USER, so the container runs as root",
+				"4 IAC_MISSING_HEALTHCHECK the final stage has no HEALTHCHECK",
+			},
+		},
+		{
+			"hardened",
+			"FROM node:20-alpine@sha256:abc AS base\nUSER node\nHEALTHCHECK CMD wget -q localhost:3000\n\nFROM base\nENV SECRET_FILE=/run/secrets/key\n",
+			nil,
+		},
+		{
+			"root user",
+			"FROM registry.local:5000/app\nUSER 0:0\nHEALTHCHECK NONE\n",
+			[]string{
+				"1 IAC_UNPINNED_IMAGE image registry.local:5000/app has no tag, so it pulls latest",
+				"1 IAC_ROOT_USER the final stage runs as USER 0:0",
+				"1 IAC_MISSING_HEALTHCHECK the final stage has no HEALTHCHECK",
+			},
+		},
+		{
+			"variable image and legacy ENV",
+			"ARG BASE=debian:12\nFROM $BASE\nENV ADMIN_PASSWORD changeme\nUSER app\nHEALTHCHECK CMD true\n",
+			[]string{"3 IAC_HARDCODED_CREDENTIAL ENV sets ADMIN_PASSWORD to a hard-coded value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, findings("Dockerfile", "dockerfile", tt.src))
+		})
+	}
+}
+
+func TestCompose(t *testing.T) {
+	src := `services:
+  grafana:
+    image: grafana/grafana:latest
+    environment:
+      - GF_SECURITY_ADMIN_PASSWORD=admin
+      - GF_SECURITY_ADMIN_USER=admin
+      - OPENAI_API_KEY=${OPENAI_API_KEY}
+  db:
+    image: postgres:16
+    user: root
+    privileged: true
+    environment:
+      POSTGRES_PASSWORD: "s3cret"
+      POSTGRES_PASSWORD_FILE: /run/secrets/pg
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+  worker:
+    build: .
+    healthcheck:
+      disable: true
+volumes:
+  data:
+`
+	assert.Equal(t, []string{
+		"3 IAC_UNPINNED_IMAGE image grafana/grafana:latest is not pinned to a version",
+		"5 IAC_HARDCODED_CREDENTIAL service grafana sets GF_SECURITY_ADMIN_PASSWORD to a hard-coded value",
+		"2 IAC_MISSING_HEALTHCHECK service grafana has no healthcheck",
+		"10 IAC_ROOT_USER service db runs as user root",
+		"11 IAC_ROOT_USER service db runs privileged",
+		"13 IAC_HARDCODED_CREDENTIAL service db sets POSTGRES_PASSWORD to a hard-coded value",
+		"17 IAC_MISSING_HEALTHCHECK service worker has no healthcheck",
+	}, findings("deploy/docker-compose.yml", "yaml", src))
+
+	assert.Empty(t, findings("values.yaml", "yaml", "services:\n  enabled: true\n"), "not every services key is compose")
+}
+
+func TestKubernetes(t *testing.T) {
+	src := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ignored
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      initContainers:
+        - name: migrate
+          image: api:1.4.0
+      containers:
+        - name: api
+          image: api
+          env:
+            - name: DB_PASSWORD
+              value: hunter2
+            - name: API_TOKEN
+              valueFrom:
+                secretKeyRef: {name: api, key: token}
+          readinessProbe:
+            httpGet: {path: /ready, port: 8080}
+        - name: sidecar
+          image: proxy:latest
+          securityContext:
+            privileged: true
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: report
+              image: report:2.0
+              securityContext:
+                runAsUser: 0
+`
+	assert.Equal(t, []string{
+		"20 IAC_UNPINNED_IMAGE image api has no tag, so it pulls latest",
+		"23 IAC_HARDCODED_CREDENTIAL container api sets DB_PASSWORD to a hard-coded value",
+		"30 IAC_UNPINNED_IMAGE image proxy:latest is not pinned to a version",
+		"32 IAC_ROOT_USER container sidecar runs privileged",
+		"29 IAC_MISSING_HEALTHCHECK container sidecar has no liveness or readiness probe",
+		"47 IAC_ROOT_USER container report runs as user 0",
+	}, findings("k8s/api.yaml", "yaml", src))
+}
+
+func TestKubernetesList(t *testing.T) {
+	src := `apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: Pod
+    metadata:
+      name: debug
+    spec:
+      containers:
+        - name: shell
+          image: busybox:1.36
+          securityContext: {runAsNonRoot: true}
+          livenessProbe: {exec: {command: [true]}}
+`
+	assert.Empty(t, findings("list.yaml", "yaml", src))
+
+	src = strings.Replace(src, "runAsNonRoot: true", "runAsNonRoot: false", 1)
+	assert.Equal(t, []string{
+		"10 IAC_ROOT_USER container shell may run as root: nothing sets runAsNonRoot or a non-zero runAsUser",
+	}, findings("list.yaml", "yaml", src))
+}
@@ -0,0 +1,96 @@
+package iac
+
+import (
+	"strconv"
+
+	"tech-debt-collector/internal/models"
+)
+
+// podSpecs locates the pod spec of each workload kind. Jobs run to
+// completion, so they need no probes.
+var podSpecs = map[string]struct {
+	path        []string
+	longRunning bool
+}{
+	"Pod":                   {[]string{"spec"}, true},
+	"Deployment":            {[]string{"spec", "template", "spec"}, true},
+	"StatefulSet":           {[]string{"spec", "template", "spec"}, true},
+	"DaemonSet":             {[]string{"spec", "template", "spec"}, true},
+	"ReplicaSet":            {[]string{"spec", "template", "spec"}, true},
+	"ReplicationController": {[]string{"spec", "template", "spec"}, true},
+	"Job":                   {[]string{"spec", "template", "spec"}, false},
+	"CronJob":               {[]string{"spec", "jobTemplate", "spec", "template", "spec"}, false},
+}
+
+// analyzeKubernetes checks the containers of a workload: their images,
+// literal env values, security context and probes
+func analyzeKubernetes(doc *node) []models.DebtItem {
+	kind := doc.get("kind").str()
+	if kind == "List" {
+		var items []models.DebtItem
+		for _, obj := range doc.get("items").list() {
+			items = append(items, analyzeKubernetes(obj)...)
+		}
+		return items
+	}
+	spec, ok := podSpecs[kind]
+	if !ok {
+		return nil
+	}
+	pod := doc.lookup(spec.path...)
+	if pod == nil {
+		return nil
+	}
+	podContext := pod.get("securityContext")
+
+	var items []models.DebtItem
+	for _, group := range []string{"initContainers", "containers"} {
+		for _, c := range pod.get(group).list() {
+			name := c.get("name").str()
+			if image := c.get("image"); image != nil {
+				if item, ok := imageItem(image.str(), image.line); ok {
+					items = append(items, item)
+				}
+			}
+
+			for _, env := range c.get("env").list() {
+				if value := env.get("value"); value != nil {
+					if item, ok := credentialItem(env.get("name").str(), value.str(), value.line, "container "+name); ok {
+						items = append(items, item)
+					}
+				}
+			}
+
+			if item, ok := rootItem(name, c, c.get("securityContext"), podContext); ok {
+				items = append(items, item)
+			}
+
+			if group == "containers" && spec.longRunning && c.get("livenessProbe") == nil && c.get("readinessProbe") == nil {
+				items = append(items, newItem(c.line, TypeMissingHealthcheck, "container %s has no liveness or readiness probe", name))
+			}
+		}
+	}
+	return items
+}
+
+// rootItem reports a container that runs privileged, as user 0, or without
+// anything in its own or its pod's security context keeping it off root
+func rootItem(name string, c, context, podContext *node) (models.DebtItem, bool) {
+	if p := context.get("privileged"); p.str() == "true" {
+		return newItem(p.line, TypeRootUser, "container %s runs privileged", name), true
+	}
+
+	// The container's settings override the pod's
+	for _, ctx := range []*node{context, podContext} {
+		if user := ctx.get("runAsUser"); user != nil {
+			if uid, err := strconv.Atoi(user.str()); err == nil && uid == 0 {
+				return newItem(user.line, TypeRootUser, "container %s runs as user 0", name), true
+			}
+			return models.DebtItem{}, false
+		}
+		if ctx.get("runAsNonRoot").str() == "true" {
+			return models.DebtItem{}, false
+		}
+	}
+	return newItem(c.line, TypeRootUser, "container %s may run as root: nothing sets runAsNonRoot or a non-zero runAsUser", name), true
+}
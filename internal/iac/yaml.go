@@ -0,0 +1,361 @@
+package iac
+
+import (
+	"strconv"
+	"strings"
+)
+
+// node is a YAML value: a scalar, a mapping or a sequence. Only the block
+// and flow styles used by compose files and Kubernetes manifests are read;
+// anchors, tags and aliases are kept as scalar text, and lines that don't
+// parse, such as template directives, are skipped.
+type node struct {
+	line   int
+	scalar string
+	pairs  []pair  // Set for mappings
+	items  []*node // Set for sequences
+	isSeq  bool
+}
+
+// pair is one entry of a mapping
+type pair struct {
+	key   string
+	line  int
+	value *node
+}
+
+// get returns the value of a mapping key, or nil
+func (n *node) get(key string) *node {
+	if n == nil {
+		return nil
+	}
+	for _, p := range n.pairs {
+		if p.key == key {
+			return p.value
+		}
+	}
+	return nil
+}
+
+// lookup follows a path of mapping keys
+func (n *node) lookup(keys ...string) *node {
+	for _, k := range keys {
+		n = n.get(k)
+	}
+	return n
+}
+
+// list returns a sequence's items, or nil for anything else
+func (n *node) list() []*node {
+	if n == nil {
+		return nil
+	}
+	return n.items
+}
+
+// str returns a scalar's text, or "" for anything else
+func (n *node) str() string {
+	if n == nil {
+		return ""
+	}
+	return n.scalar
+}
+
+// yamlLine is a line without its comment and indentation
+type yamlLine struct {
+	indent int
+	text   string
+	num    int
+}
+
+// parseYAML splits a file into its documents and parses each one
+func parseYAML(lines []string) []*node {
+	var docs []*node
+	var doc []yamlLine
+	flush := func() {
+		if len(doc) > 0 {
+			p := &yamlParser{lines: doc}
+			docs = append(docs, p.parseBlock())
+		}
+		doc = nil
+	}
+
+	for i, raw := range lines {
+		if strings.HasPrefix(raw, "---") || strings.HasPrefix(raw, "...") {
+			flush()
+			continue
+		}
+		text := strings.TrimRight(stripComment(raw), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "%") {
+			continue
+		}
+		doc = append(doc, yamlLine{indent: len(text) - len(trimmed), text: trimmed, num: i + 1})
+	}
+	flush()
+	return docs
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+func (p *yamlParser) done() bool { return p.i >= len(p.lines) }
+
+// parseBlock parses the value starting at the current line
+func (p *yamlParser) parseBlock() *node {
+	l := p.lines[p.i]
+	switch {
+	case isSeqItem(l.text):
+		return p.parseSeq(l.indent)
+	case isKeyLine(l.text):
+		return p.parseMap(l.indent)
+	}
+	p.i++
+	return scalarNode(l.text, l.num)
+}
+
+// parseSeq parses the items of a block sequence at indent. An item's inline
+// content is reparsed as a block indented past the dash.
+func (p *yamlParser) parseSeq(indent int) *node {
+	n := &node{line: p.lines[p.i].num, isSeq: true}
+	for !p.done() {
+		l := p.lines[p.i]
+		if l.indent > indent {
+			p.i++ // Malformed: deeper than the item it would belong to
+			continue
+		}
+		if l.indent < indent || !isSeqItem(l.text) {
+			break
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if rest == "" {
+			p.i++
+			if !p.done() && p.lines[p.i].indent > indent {
+				n.items = append(n.items, p.parseBlock())
+			} else {
+				n.items = append(n.items, &node{line: l.num})
+			}
+			continue
+		}
+		p.lines[p.i] = yamlLine{indent: indent + len(l.text) - len(rest), text: rest, num: l.num}
+		n.items = append(n.items, p.parseBlock())
+	}
+	return n
+}
+
+// parseMap parses the entries of a block mapping at indent
+func (p *yamlParser) parseMap(indent int) *node {
+	n := &node{line: p.lines[p.i].num}
+	for !p.done() {
+		l := p.lines[p.i]
+		if l.indent > indent {
+			p.i++
+			continue
+		}
+		if l.indent < indent || isSeqItem(l.text) {
+			break
+		}
+		key, rest, ok := splitKey(l.text)
+		p.i++
+		if !ok {
+			continue
+		}
+
+		var value *node
+		switch {
+		case rest == "" || isProperty(rest) && !strings.ContainsAny(rest, " \t"):
+			next := p.i < len(p.lines) && (p.lines[p.i].indent > indent || p.lines[p.i].indent == indent && isSeqItem(p.lines[p.i].text))
+			if next {
+				value = p.parseBlock()
+			} else {
+				value = &node{line: l.num}
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			var text []string
+			for !p.done() && p.lines[p.i].indent > indent {
+				text = append(text, p.lines[p.i].text)
+				p.i++
+			}
+			value = &node{line: l.num, scalar: strings.Join(text, "\n")}
+		case (rest[0] == '[' || rest[0] == '{') && !strings.HasPrefix(rest, "{{"):
+			for !balanced(rest) && !p.done() && p.lines[p.i].indent > indent {
+				rest += " " + p.lines[p.i].text
+				p.i++
+			}
+			value, _ = parseFlow(rest, l.num)
+		default:
+			value = scalarNode(rest, l.num)
+		}
+		n.pairs = append(n.pairs, pair{key: key, line: l.num, value: value})
+	}
+	return n
+}
+
+// parseFlow parses a flow sequence or mapping such as ["CMD", "true"] or
+// {a: 1}, returning the rest of the text
+func parseFlow(s string, line int) (*node, string) {
+	s = strings.TrimLeft(s, " ")
+	if s == "" {
+		return &node{line: line}, ""
+	}
+	switch s[0] {
+	case '[', '{':
+		isSeq := s[0] == '['
+		n := &node{line: line, isSeq: isSeq}
+		s = s[1:]
+		for {
+			s = strings.TrimLeft(s, " ,")
+			if s == "" {
+				return n, ""
+			}
+			if s[0] == ']' || s[0] == '}' {
+				return n, s[1:]
+			}
+			var item *node
+			if isSeq {
+				item, s = parseFlow(s, line)
+				n.items = append(n.items, item)
+				continue
+			}
+			var key *node
+			key, s = parseFlowScalar(s, line, true)
+			s = strings.TrimLeft(s, " ")
+			if strings.HasPrefix(s, ":") {
+				item, s = parseFlow(s[1:], line)
+			} else {
+				item = &node{line: line}
+			}
+			n.pairs = append(n.pairs, pair{key: key.scalar, line: line, value: item})
+		}
+	}
+	return parseFlowScalar(s, line, false)
+}
+
+// parseFlowScalar reads a scalar inside a flow collection, up to the next
+// separator; keys also end at a colon
+func parseFlowScalar(s string, line int, key bool) (*node, string) {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		end := closingQuote(s)
+		return scalarNode(s[:end], line), s[end:]
+	}
+	end := strings.IndexAny(s, ",]}")
+	if key {
+		if c := strings.Index(s, ":"); c >= 0 && (end < 0 || c < end) {
+			end = c
+		}
+	}
+	if end < 0 {
+		end = len(s)
+	}
+	return scalarNode(strings.TrimSpace(s[:end]), line), s[end:]
+}
+
+// scalarNode unquotes a scalar and drops its anchor or tag
+func scalarNode(text string, line int) *node {
+	text = strings.TrimSpace(text)
+	for isProperty(text) && strings.Contains(text, " ") {
+		text = strings.TrimSpace(text[strings.IndexByte(text, ' '):])
+	}
+	switch {
+	case len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"':
+		if s, err := strconv.Unquote(text); err == nil {
+			text = s
+		} else {
+			text = text[1 : len(text)-1]
+		}
+	case len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'':
+		text = strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	return &node{line: line, scalar: text}
+}
+
+// splitKey splits "key: value" at the first colon outside quotes that is
+// followed by a space or ends the line
+func splitKey(text string) (string, string, bool) {
+	start := 0
+	if text[0] == '"' || text[0] == '\'' {
+		start = closingQuote(text)
+	}
+	for i := start; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t') {
+			key := scalarNode(text[:i], 0).scalar
+			return key, strings.TrimSpace(text[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+func isKeyLine(text string) bool {
+	_, _, ok := splitKey(text)
+	return ok && text[0] != '[' && text[0] != '{'
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isProperty reports whether text starts with an anchor or a tag
+func isProperty(text string) bool {
+	return strings.HasPrefix(text, "&") || strings.HasPrefix(text, "!")
+}
+
+// closingQuote returns the index just past the quoted string at the start
+// of s
+func closingQuote(s string) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == q:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// balanced reports whether every bracket opened in a flow collection is
+// closed
+func balanced(s string) bool {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			i = closingQuote(s[i:]) + i - 1
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// stripComment removes a # comment, which starts a line or follows a space,
+// outside quotes
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t:[{,-", rune(line[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,78 @@
+package iac
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseYAML(t *testing.T) {
+	src := `# comment
+services:
+  web:
+    image: "nginx:1.25" # pinned
+    ports: ["80:80", '443:443']
+    environment:
+      - A=1
+      - B=two # trailing
+    labels: {team: core, "tier": web}
+    command: >
+      run
+      --fast
+  db:
+    image: &img postgres
+list:
+- a
+- b: 1
+  c: 2
+-
+  - nested
+{{- if .Values.enabled }}
+after: yes
+---
+kind: Second
+`
+	docs := parseYAML(splitTestLines(src))
+	if !assert.Len(t, docs, 2) {
+		return
+	}
+	doc := docs[0]
+
+	web := doc.lookup("services", "web")
+	assert.Equal(t, "nginx:1.25", web.get("image").str())
+	assert.Equal(t, 4, web.get("image").line)
+	if ports := web.get("ports"); assert.True(t, ports.isSeq) && assert.Len(t, ports.items, 2) {
+		assert.Equal(t, "443:443", ports.items[1].str())
+	}
+	env := web.get("environment")
+	if assert.Len(t, env.items, 2) {
+		assert.Equal(t, "B=two", env.items[1].str())
+		assert.Equal(t, 8, env.items[1].line)
+	}
+	assert.Equal(t, "core", web.lookup("labels", "team").str())
+	assert.Equal(t, "web", web.lookup("labels", "tier").str())
+	assert.Equal(t, "run\n--fast", web.get("command").str())
+	assert.Equal(t, "postgres", doc.lookup("services", "db", "image").str())
+
+	list := doc.get("list")
+	if assert.Len(t, list.items, 3) {
+		assert.Equal(t, "a", list.items[0].str())
+		assert.Equal(t, "2", list.items[1].get("c").str())
+		assert.Equal(t, 17, list.items[1].line)
+		assert.Equal(t, "nested", list.items[2].items[0].str())
+	}
+	assert.Equal(t, "yes", doc.get("after").str(), "template lines are skipped")
+	assert.Equal(t, "Second", docs[1].get("kind").str())
+}
+
+func TestStripComment(t *testing.T) {
+	for line, want := range map[string]string{
+		"a: b # c":            "a: b ",
+		"# whole line":        "",
+		"url: http://x/#frag": "url: http://x/#frag",
+		`a: "# not" # yes`:    `a: "# not" `,
+		"a: it's # x":         "a: it's ",
+	} {
+		assert.Equal(t, want, stripComment(line), line)
+	}
+}
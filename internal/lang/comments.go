@@ -0,0 +1,237 @@
+package lang
+
+import "strings"
+
+// CommentLine is the comment text found on one source line
+type CommentLine struct {
+	Line  int // 1-based
+	Text  string
+	Block int // Lines of the same comment block share a number
+}
+
+// lexState is the lexer state carried from one line to the next
+type lexState int
+
+const (
+	inCode lexState = iota
+	inBlock
+	inString
+	inDocString
+)
+
+// Comments extracts the comment text of each line using the language's
+// comment and string syntax, so that comment openers inside string literals
+// are not mistaken for comments. Python docstrings count as comments.
+// Lines without comments are omitted.
+//
+// A block comment or docstring forms one block, as do runs of line comments
+// on consecutive lines where each continuation has no code before it.
+func Comments(l Language, lines []string) []CommentLine {
+	var out []CommentLine
+	block := 0
+	for i, ll := range lex(l, lines) {
+		if len(ll.comment) > 0 {
+			if !ll.continues {
+				block++
+			}
+			out = append(out, CommentLine{Line: i + 1, Text: strings.Join(ll.comment, " "), Block: block})
+		}
+	}
+	return out
+}
+
+// Code returns each line with its comments and docstrings removed, and
+// string literals kept
+func Code(l Language, lines []string) []string {
+	out := make([]string, len(lines))
+	for i, ll := range lex(l, lines) {
+		out[i] = ll.code
+	}
+	return out
+}
+
+// lexedLine is one source line split into comment text and code
+type lexedLine struct {
+	comment   []string
+	code      string
+	continues bool // Starts inside a comment, or continues a run of line comments
+}
+
+// lex runs the lexer over lines, carrying its state from one to the next
+func lex(l Language, lines []string) []lexedLine {
+	out := make([]lexedLine, len(lines))
+	state := inCode
+	var cur token            // The block comment or string being read
+	prevLineComment := false // The previous line ended in a line comment
+
+	for i, line := range lines {
+		var parts []string
+		var code strings.Builder
+		pos := 0
+		continues := state == inBlock || state == inDocString
+		endsInLineComment := false
+
+		for pos < len(line) {
+			switch state {
+			case inBlock:
+				end := strings.Index(line[pos:], cur.close)
+				if end < 0 {
+					parts = append(parts, line[pos:])
+					pos = len(line)
+					continue
+				}
+				parts = append(parts, line[pos:pos+end])
+				pos += end + len(cur.close)
+				state = inCode
+				code.WriteByte(' ')
+
+			case inString, inDocString:
+				end := stringEnd(line, pos, cur)
+				stop := end
+				if end < 0 {
+					stop = len(line)
+				}
+				if state == inDocString {
+					parts = append(parts, line[pos:stop])
+				} else {
+					code.WriteString(line[pos:stop])
+				}
+				if end < 0 {
+					pos = len(line)
+					continue
+				}
+				if state == inString {
+					code.WriteString(cur.close)
+				}
+				pos = end + len(cur.close)
+				state = inCode
+
+			default:
+				start, tok := nextToken(l, line, pos)
+				if start < 0 {
+					code.WriteString(line[pos:])
+					pos = len(line)
+					continue
+				}
+				code.WriteString(line[pos:start])
+				if tok.kind == tokLine && len(parts) == 0 && strings.TrimSpace(line[:start]) == "" {
+					continues = prevLineComment
+				}
+				pos = start + len(tok.open)
+				cur = tok
+				switch {
+				case tok.kind == tokLine:
+					parts = append(parts, line[pos:])
+					pos = len(line)
+					endsInLineComment = true
+				case tok.kind == tokBlock:
+					state = inBlock
+				case l.DocStrings && len(tok.open) == 3 && isDocStringStart(line[:start]):
+					state = inDocString
+				default:
+					state = inString
+					code.WriteString(tok.open)
+				}
+			}
+		}
+
+		// Single-line strings never carry over; an unterminated one is a typo
+		// and resetting keeps it from hiding the rest of the file
+		if state == inString && !cur.multiline {
+			state = inCode
+		}
+
+		out[i] = lexedLine{comment: parts, code: code.String(), continues: continues}
+		prevLineComment = endsInLineComment
+	}
+
+	return out
+}
+
+type tokenKind int
+
+const (
+	tokLine tokenKind = iota
+	tokBlock
+	tokString
+)
+
+type token struct {
+	kind        tokenKind
+	open, close string
+	escape      bool
+	multiline   bool
+}
+
+// nextToken finds the earliest comment or string opener in line at or after
+// pos. When two openers start at the same place (Lua "--" vs "--[[", Python
+// `"` vs `"""`), the longer wins.
+func nextToken(l Language, line string, pos int) (int, token) {
+	for i := pos; i < len(line); i++ {
+		var best token
+		found := false
+		consider := func(t token) {
+			if strings.HasPrefix(line[i:], t.open) && (!found || len(t.open) > len(best.open)) {
+				best, found = t, true
+			}
+		}
+
+		for _, tok := range l.LineComments {
+			if commentMayStart(l.CommentStart, line, i) {
+				consider(token{kind: tokLine, open: tok})
+			}
+		}
+		for _, pair := range l.BlockComments {
+			// Ruby's =begin only counts at the start of a line
+			if pair[0][0] == '=' && i != 0 {
+				continue
+			}
+			consider(token{kind: tokBlock, open: pair[0], close: pair[1]})
+		}
+		for _, d := range l.Strings {
+			consider(token{kind: tokString, open: d.Open, close: d.Close, escape: d.Escape, multiline: d.Multiline})
+		}
+
+		if found {
+			return i, best
+		}
+	}
+	return -1, token{}
+}
+
+// commentMayStart applies the language's placement rule to a line comment
+// token at line[i]
+func commentMayStart(p Placement, line string, i int) bool {
+	switch p {
+	case WordStart:
+		return i == 0 || strings.IndexByte(" \t;|&(", line[i-1]) >= 0
+	case LineStart:
+		return strings.TrimSpace(line[:i]) == ""
+	}
+	return true
+}
+
+// stringEnd returns the index of the string's closer in line at or after
+// pos, skipping backslash escapes if the string honours them, or -1
+func stringEnd(line string, pos int, str token) int {
+	for i := pos; i < len(line); i++ {
+		if str.escape && line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(line[i:], str.close) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isDocStringStart reports whether a triple-quoted string preceded by
+// prefix stands alone as a statement, allowing r/u string prefixes
+func isDocStringStart(prefix string) bool {
+	switch strings.TrimSpace(prefix) {
+	case "", "r", "R", "u", "U":
+		return true
+	}
+	return false
+}
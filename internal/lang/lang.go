@@ -0,0 +1,238 @@
+package lang
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Language describes how files of one language are recognised and how
+// they write comments and string literals
+type Language struct {
+	ID            string
+	Extensions    []string
+	Filenames     []string    // Exact base names, e.g. "Makefile"
+	Interpreters  []string    // Shebang interpreters, e.g. "python3"
+	LineComments  []string    // e.g. "//", "#"
+	BlockComments [][2]string // Open/close pairs, e.g. {"/*", "*/"}
+	Strings       []Delim     // String literals, so markers inside them are ignored
+	DocStrings    bool        // Triple-quoted strings standing alone are documentation (Python)
+	CommentStart  Placement   // Where line comments may begin
+}
+
+// Delim describes one kind of string literal
+type Delim struct {
+	Open      string
+	Close     string
+	Escape    bool // Backslash escapes the next character
+	Multiline bool // May span lines
+}
+
+// Placement restricts where a line comment token starts a comment
+type Placement int
+
+const (
+	Anywhere  Placement = iota // C-style "//"
+	WordStart                  // Shell "#": not inside a word, as in ${#x} or a#b
+	LineStart                  // Dockerfile "#": only at the start of a line
+)
+
+var (
+	cStyleBlock = [][2]string{{"/*", "*/"}}
+	cStyleLine  = []string{"//"}
+	hashLine    = []string{"#"}
+
+	dq         = Delim{Open: `"`, Close: `"`, Escape: true}
+	sq         = Delim{Open: `'`, Close: `'`, Escape: true}
+	rawSQ      = Delim{Open: `'`, Close: `'`, Multiline: true}
+	tripleDQ   = Delim{Open: `"""`, Close: `"""`, Escape: true, Multiline: true}
+	tripleSQ   = Delim{Open: `'''`, Close: `'''`, Escape: true, Multiline: true}
+	cStrings   = []Delim{dq, sq}
+	goStrings  = []Delim{dq, sq, {Open: "`", Close: "`", Multiline: true}}
+	jsStrings  = []Delim{dq, sq, {Open: "`", Close: "`", Escape: true, Multiline: true}}
+	jvmStrings = []Delim{tripleDQ, dq, sq}
+	pyStrings  = []Delim{tripleDQ, tripleSQ, dq, sq}
+	shStrings  = []Delim{{Open: `"`, Close: `"`, Escape: true, Multiline: true}, rawSQ}
+)
+
+// languages is the table of known languages. IDs are lower-case and stable,
+// since they are stored on reported debt items.
+var languages = []Language{
+	{ID: "go", Extensions: []string{".go"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: goStrings},
+	{ID: "python", Extensions: []string{".py", ".pyw", ".pyi"}, Filenames: []string{"SConstruct", "SConscript"}, Interpreters: []string{"python", "python2", "python3"},
+		LineComments: hashLine, Strings: pyStrings, DocStrings: true},
+	{ID: "javascript", Extensions: []string{".js", ".jsx", ".mjs", ".cjs"}, Interpreters: []string{"node", "nodejs"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: jsStrings},
+	{ID: "typescript", Extensions: []string{".ts", ".tsx", ".mts", ".cts"}, Interpreters: []string{"deno", "ts-node"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: jsStrings},
+	{ID: "java", Extensions: []string{".java"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: jvmStrings},
+	{ID: "kotlin", Extensions: []string{".kt", ".kts"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: jvmStrings},
+	{ID: "scala", Extensions: []string{".scala", ".sc"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: jvmStrings},
+	{ID: "groovy", Extensions: []string{".groovy", ".gradle"}, Filenames: []string{"Jenkinsfile"}, Interpreters: []string{"groovy"},
+		LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: []Delim{tripleDQ, tripleSQ, dq, sq}},
+	{ID: "c", Extensions: []string{".c", ".h"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: cStrings},
+	{ID: "cpp", Extensions: []string{".cpp", ".cc", ".cxx", ".hpp", ".hh", ".hxx"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: cStrings},
+	{ID: "csharp", Extensions: []string{".cs"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: jvmStrings},
+	{ID: "swift", Extensions: []string{".swift"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: []Delim{tripleDQ, dq}},
+	// Rust has no single-quoted strings, and lifetimes ('a) would confuse a char literal rule
+	{ID: "rust", Extensions: []string{".rs"}, LineComments: cStyleLine, BlockComments: cStyleBlock, Strings: []Delim{{Open: `"`, Close: `"`, Escape: true, Multiline: true}}},
+	{ID: "php", Extensions: []string{".php"}, Interpreters: []string{"php"}, LineComments: []string{"//", "#"}, BlockComments: cStyleBlock, Strings: cStrings},
+	{ID: "ruby", Extensions: []string{".rb", ".rake", ".gemspec"}, Filenames: []string{"Gemfile", "Rakefile", "Vagrantfile", "Podfile"}, Interpreters: []string{"ruby"},
+		LineComments: hashLine, BlockComments: [][2]string{{"=begin", "=end"}}, Strings: cStrings},
+	{ID: "perl", Extensions: []string{".pl", ".pm"}, Interpreters: []string{"perl"}, LineComments: hashLine, Strings: cStrings, CommentStart: WordStart},
+	{ID: "lua", Extensions: []string{".lua"}, Interpreters: []string{"lua"}, LineComments: []string{"--"}, BlockComments: [][2]string{{"--[[", "]]"}},
+		Strings: []Delim{dq, sq, {Open: "[[", Close: "]]", Multiline: true}}},
+	{ID: "shell", Extensions: []string{".sh", ".bash", ".zsh", ".ksh"}, Filenames: []string{".bashrc", ".bash_profile", ".profile", ".zshrc"}, Interpreters: []string{"sh", "bash", "zsh", "ksh", "dash", "ash"},
+		LineComments: hashLine, Strings: shStrings, CommentStart: WordStart},
+	{ID: "makefile", Extensions: []string{".mk", ".mak"}, Filenames: []string{"Makefile", "makefile", "GNUmakefile"}, Interpreters: []string{"make"}, LineComments: hashLine},
+	{ID: "dockerfile", Extensions: []string{".dockerfile"}, Filenames: []string{"Dockerfile", "Containerfile"}, LineComments: hashLine, CommentStart: LineStart},
+	{ID: "starlark", Extensions: []string{".bzl", ".star"}, Filenames: []string{"BUILD", "BUILD.bazel", "WORKSPACE", "Tiltfile"}, LineComments: hashLine, Strings: pyStrings, DocStrings: true},
+	{ID: "cmake", Extensions: []string{".cmake"}, Filenames: []string{"CMakeLists.txt"}, LineComments: hashLine, Strings: []Delim{{Open: `"`, Close: `"`, Escape: true, Multiline: true}}},
+	{ID: "yaml", Extensions: []string{".yml", ".yaml"}, LineComments: hashLine, Strings: []Delim{dq, {Open: `'`, Close: `'`}}, CommentStart: WordStart},
+	{ID: "toml", Extensions: []string{".toml"}, LineComments: hashLine, Strings: []Delim{tripleDQ, {Open: `'''`, Close: `'''`, Multiline: true}, dq, {Open: `'`, Close: `'`}}},
+	{ID: "sql", Extensions: []string{".sql"}, LineComments: []string{"--"}, BlockComments: cStyleBlock, Strings: []Delim{{Open: `'`, Close: `'`, Multiline: true}, {Open: `"`, Close: `"`}}},
+	{ID: "html", Extensions: []string{".html", ".htm", ".xhtml", ".vue", ".svelte"}, BlockComments: [][2]string{{"<!--", "-->"}}},
+	{ID: "xml", Extensions: []string{".xml", ".xsd", ".svg"}, BlockComments: [][2]string{{"<!--", "-->"}}},
+	{ID: "css", Extensions: []string{".css", ".scss", ".less"}, BlockComments: cStyleBlock, Strings: cStrings},
+}
+
+var (
+	byID        = make(map[string]*Language)
+	byExt       = make(map[string]*Language)
+	byFilename  = make(map[string]*Language)
+	byInterp    = make(map[string]*Language)
+	modelineRes = []*regexp.Regexp{
+		regexp.MustCompile(`-\*-.*?\bmode:\s*([\w+#-]+)`),                   // Emacs: -*- mode: python -*-
+		regexp.MustCompile(`-\*-\s*([\w+#-]+)\s*-\*-`),                      // Emacs: -*- python -*-
+		regexp.MustCompile(`\b(?:vim?|ex):.*?\b(?:ft|filetype)=([\w+#-]+)`), // Vim: vim: set ft=python:
+	}
+	// modelineAliases maps editor mode names onto language IDs
+	modelineAliases = map[string]string{
+		"sh": "shell", "bash": "shell", "zsh": "shell", "js": "javascript",
+		"ts": "typescript", "py": "python", "c++": "cpp", "make": "makefile",
+		"rb": "ruby", "cs": "csharp", "docker": "dockerfile", "yml": "yaml",
+	}
+)
+
+func init() {
+	for i := range languages {
+		l := &languages[i]
+		byID[l.ID] = l
+		for _, ext := range l.Extensions {
+			byExt[ext] = l
+		}
+		for _, name := range l.Filenames {
+			byFilename[name] = l
+		}
+		for _, interp := range l.Interpreters {
+			byInterp[interp] = l
+		}
+	}
+}
+
+// HeadSize is how many leading bytes of a file Detect looks at
+const HeadSize = 1024
+
+// Head returns the leading bytes of data that Detect needs
+func Head(data []byte) []byte {
+	if len(data) > HeadSize {
+		return data[:HeadSize]
+	}
+	return data
+}
+
+// Lookup returns the language with the given ID
+func Lookup(id string) (Language, bool) {
+	l, ok := byID[id]
+	if !ok {
+		return Language{}, false
+	}
+	return *l, true
+}
+
+// Detect returns the language ID of a file from its name and the first
+// bytes of its content, or "" if unknown. An explicit editor modeline wins,
+// then well-known filenames, then the shebang, then the extension.
+func Detect(filePath string, head []byte) string {
+	if id := fromModeline(head); id != "" {
+		return id
+	}
+
+	base := path.Base(strings.ReplaceAll(filePath, `\`, "/"))
+	if l, ok := byFilename[base]; ok {
+		return l.ID
+	}
+	// Variants such as "Dockerfile.prod" or "Makefile.linux"
+	if i := strings.IndexByte(base, '.'); i > 0 {
+		if l, ok := byFilename[base[:i]]; ok && (l.ID == "dockerfile" || l.ID == "makefile") {
+			return l.ID
+		}
+	}
+
+	if id := fromShebang(head); id != "" {
+		return id
+	}
+
+	if l, ok := byExt[strings.ToLower(path.Ext(base))]; ok {
+		return l.ID
+	}
+	return ""
+}
+
+// fromShebang reads the interpreter from a "#!" first line
+func fromShebang(head []byte) string {
+	if !bytes.HasPrefix(head, []byte("#!")) {
+		return ""
+	}
+	line := head[2:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+	interp := path.Base(fields[0])
+	if interp == "env" {
+		// #!/usr/bin/env [-S] python3
+		interp = ""
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") && !strings.Contains(f, "=") {
+				interp = f
+				break
+			}
+		}
+	}
+
+	// Strip version suffixes such as python3.11
+	interp = strings.TrimRight(interp, "0123456789.")
+	if l, ok := byInterp[interp]; ok {
+		return l.ID
+	}
+	return ""
+}
+
+// fromModeline looks for an Emacs or Vim modeline in the first lines
+func fromModeline(head []byte) string {
+	lines := bytes.SplitN(head, []byte("\n"), 6)
+	if len(lines) > 5 {
+		lines = lines[:5]
+	}
+
+	for _, line := range lines {
+		for _, re := range modelineRes {
+			m := re.FindSubmatch(line)
+			if m == nil {
+				continue
+			}
+			mode := strings.ToLower(string(m[1]))
+			if alias, ok := modelineAliases[mode]; ok {
+				mode = alias
+			}
+			if _, ok := byID[mode]; ok {
+				return mode
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package lang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		path     string
+		head     string
+		expected string
+	}{
+		{"main.go", "package main", "go"},
+		{"src/app.TS", "", "typescript"},
+		{"Makefile", "all:\n", "makefile"},
+		{"build/Dockerfile.prod", "FROM alpine", "dockerfile"},
+		{"Jenkinsfile", "pipeline {", "groovy"},
+		{"Gemfile", "source 'x'", "ruby"},
+		{"scripts/deploy", "#!/bin/bash\nset -e\n", "shell"},
+		{"tools/gen", "#!/usr/bin/env python3\n", "python"},
+		{"tools/gen", "#!/usr/bin/env -S python3.11 -u\n", "python"},
+		{"tools/run", "#!/usr/local/bin/node\n", "javascript"},
+		{"notes.txt", "# -*- mode: python -*-\n", "python"},
+		{"legacy.h", "// vim: set ft=cpp :\n", "cpp"},
+		{"run.inc", "#!/bin/sh\n# vim: ft=bash\n", "shell"},
+		{"README", "hello", ""},
+		{"binary", "\x00\x01\x02", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Detect(tt.path, []byte(tt.head)), tt.path)
+	}
+}
+
+func TestComments(t *testing.T) {
+	goLang, _ := Lookup("go")
+	lines := []string{
+		"x := 1 // trailing",
+		"/* block",
+		"   continues */ y := 2",
+		"z := 3",
+		"a /* inline */ b // and line",
+	}
+	assert.Equal(t, []CommentLine{
+		{Line: 1, Text: " trailing", Block: 1},
+		{Line: 2, Text: " block", Block: 2},
+		{Line: 3, Text: "   continues ", Block: 2},
+		{Line: 5, Text: " inline   and line", Block: 3},
+	}, Comments(goLang, lines))
+
+	lua, _ := Lookup("lua")
+	assert.Equal(t, []CommentLine{{Line: 1, Text: " block ", Block: 1}, {Line: 2, Text: " line", Block: 2}},
+		Comments(lua, []string{"--[[ block ]] x = 1", "-- line"}))
+
+	// Comment openers inside strings are not comments; docstrings are
+	assert.Equal(t, []CommentLine{{Line: 1, Text: " real", Block: 1}},
+		Comments(goLang, []string{`s := "// no" + "/*" // real`, "t := `", "// raw", "`"}))
+	py, _ := Lookup("python")
+	assert.Equal(t, []CommentLine{{Line: 2, Text: "doc", Block: 1}, {Line: 3, Text: " c", Block: 2}},
+		Comments(py, []string{`x = "#"`, `    """doc"""`, `y = '"""' # c`}))
+
+	// Consecutive line comments form one block unless code precedes them
+	assert.Equal(t, []CommentLine{
+		{Line: 1, Text: " a", Block: 1},
+		{Line: 2, Text: " b", Block: 1},
+		{Line: 3, Text: " c", Block: 2},
+		{Line: 5, Text: " d", Block: 3},
+	}, Comments(goLang, []string{"x() // a", "  // b", "y() // c", "", "// d"}))
+
+	html, _ := Lookup("html")
+	assert.Equal(t, []CommentLine{{Line: 2, Text: " TODO: markup ", Block: 1}},
+		Comments(html, []string{"<p>TODO</p>", "<!-- TODO: markup -->"}))
+}
+
+func TestCode(t *testing.T) {
+	goLang, _ := Lookup("go")
+	assert.Equal(t, []string{`s := "// no" `, "x :=   1", " ", "t := `", "// raw", "`"},
+		Code(goLang, []string{`s := "// no" // yes`, "x := /* a */ 1", "/* b */", "t := `", "// raw", "`"}))
+
+	py, _ := Lookup("python")
+	assert.Equal(t, []string{"def f():", "    ", "    return '#'  "},
+		Code(py, []string{"def f():", `    """doc"""`, "    return '#'  # c"}))
+}
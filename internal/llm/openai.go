@@ -109,7 +109,7 @@ Type: %s
 Message: %s
 Severity Score: %d/5
 File Importance: %d/5
-Frequency in File: %d
+Frequency in Repository: %d/5
 
 Format your response as:
 EXPLANATION: [your explanation]
@@ -7,18 +7,71 @@ type DebtItem struct {
 	ID                string    `json:"id"`
 	FilePath          string    `json:"file_path"`
 	LineNumber        int       `json:"line_number"`
-	Type              string    `json:"type"` // TODO, FIXME, HACK, DEPRECATED, XXX
+	EndLine           int       `json:"end_line,omitempty"` // Last line of a multi-line comment
+	Language          string    `json:"language,omitempty"`
+	Type              string    `json:"type"` // A marker such as TODO, or a detector type such as LINT_SUPPRESSION
+	Category          string    `json:"category,omitempty"`
 	Message           string    `json:"message"`
 	Severity          int       `json:"severity"`        // 1-5: low to critical
 	FileImportance    int       `json:"file_importance"` // 1-5: low to critical
-	Frequency         int       `json:"frequency"`       // How many similar items in file
+	Frequency         int       `json:"frequency"`       // 1-5: how many similar items in the repository
 	Risk              float64   `json:"risk"`            // Computed risk score (0-100)
 	DetectedAt        time.Time `json:"detected_at"`
 	LLMExplanation    string    `json:"llm_explanation"`
 	LLMPriority       string    `json:"llm_priority"` // HIGH, MEDIUM, LOW
 	LLMRecommendation string    `json:"llm_recommendation"`
+
+	// Parsed from tags such as TODO(alice, PROJ-123, 2026-12-01) and from
+	// "by 2026-01-01" or "until v2.0" in the message
+	Owner      string     `json:"owner,omitempty"`
+	IssueRefs  []string   `json:"issue_refs,omitempty"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	DueVersion string     `json:"due_version,omitempty"`
+
+	Suppression *Suppression `json:"suppression,omitempty"`
+
+	Rules      []string `json:"rules,omitempty"`       // Lint rules silenced by a LINT_SUPPRESSION item
+	TestName   string   `json:"test_name,omitempty"`   // Test disabled by a SKIPPED_TEST item
+	SkipReason string   `json:"skip_reason,omitempty"` // Why the test is skipped, if given
+
+	Symbol     string `json:"symbol,omitempty"`      // Deprecated symbol used by a DEPRECATED_USAGE item, or undocumented one of a MISSING_DOC item, e.g. config.Load
+	DeclaredAt string `json:"declared_at,omitempty"` // Where the symbol is declared, as path:line
+
+	ClusterID string `json:"cluster_id,omitempty"` // Items with similar messages across the repository share it
+
+	Locations []Location `json:"locations,omitempty"` // Every copy of a DUPLICATION item, this one first
 }
 
+// Location is a range of lines in a file
+type Location struct {
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// Suppression records the techdebt: directive that silences an item
+type Suppression struct {
+	Directive string     `json:"directive"` // ignore, ignore-next-line or snooze
+	Line      int        `json:"line"`      // Where the directive is
+	Until     *time.Time `json:"until,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	Expired   bool       `json:"expired,omitempty"` // A snooze that has run out; the item is active again
+}
+
+// Debt categories, set on DebtItem.Category
+const (
+	CategoryComment        = "comment"        // Markers such as TODO in comments
+	CategoryLint           = "lint"           // Suppressed linter and type checker warnings
+	CategoryTest           = "test"           // Disabled tests
+	CategoryStructure      = "structure"      // Long, complex or stubbed code
+	CategoryDeprecation    = "deprecation"    // Uses of deprecated APIs
+	CategoryDuplication    = "duplication"    // Copied code
+	CategoryDependency     = "dependency"     // Dependency manifests
+	CategoryInfrastructure = "infrastructure" // Dockerfiles, compose files and Kubernetes manifests
+	CategorySecurity       = "security"       // Hard-coded secrets
+	CategoryDocs           = "docs"           // Undocumented exported Go API
+)
+
 // RiskScore holds the risk assessment
 type RiskScore struct {
 	Item              *DebtItem
@@ -40,6 +93,46 @@ type Report struct {
 	DebtItems       []DebtItem `json:"debt_items"`
 	Summary         string     `json:"summary"`
 	Recommendations []string   `json:"recommendations"`
+
+	SkippedFiles []SkippedFile `json:"skipped_files,omitempty"`
+	Suppressed   []DebtItem    `json:"suppressed,omitempty"` // Silenced by techdebt: directives, not scored
+
+	DeprecatedSymbols []SymbolUsage `json:"deprecated_symbols,omitempty"` // Usage counts of deprecated symbols
+	Clusters          []Cluster     `json:"clusters,omitempty"`           // Largest groups of similar items
+	DocCoverage       []PackageDocs `json:"doc_coverage,omitempty"`       // Per Go package, least documented first
+}
+
+// Cluster is a group of items with similar messages, which one fix may
+// resolve together
+type Cluster struct {
+	ID       string `json:"id"`
+	Size     int    `json:"size"`
+	Files    int    `json:"files"` // Distinct files the items are in
+	Type     string `json:"type"`
+	Category string `json:"category,omitempty"`
+	Message  string `json:"message"` // Message of a representative item
+}
+
+// PackageDocs is how much of a Go package's exported API is documented
+type PackageDocs struct {
+	Package    string  `json:"package"` // Directory of the package
+	Name       string  `json:"name"`
+	Exported   int     `json:"exported"`   // Exported functions, methods and types, and the package itself
+	Documented int     `json:"documented"` // Those with a doc comment
+	Coverage   float64 `json:"coverage"`   // Percentage documented
+}
+
+// SymbolUsage counts the references to one deprecated symbol
+type SymbolUsage struct {
+	Symbol     string `json:"symbol"`
+	DeclaredAt string `json:"declared_at"`
+	Usages     int    `json:"usages"`
+}
+
+// SkippedFile is a matching file left out of the analysis
+type SkippedFile struct {
+	FilePath string `json:"file_path"`
+	Reason   string `json:"reason"` // generated, minified or binary
 }
 
 // ScannerConfig holds scanner configuration
@@ -48,6 +141,12 @@ type ScannerConfig struct {
 	ExcludeDirs       []string
 	IncludeExtensions []string
 	SkipHiddenDirs    bool
+	UseIgnoreFiles    bool   // Honour .gitignore/.ignore files
+	Since             string // Only scan files changed since this git ref
+	Staged            bool   // Only scan files with staged changes
+	ChangedLinesOnly  bool   // With Since/Staged, report only debt on changed lines
+	Ref               string // Scan the tree of this git commit/tag instead of the working directory
+	IncludeGenerated  bool   // Also scan generated, minified and binary files
 }
 
 // Config holds application configuration
@@ -59,4 +158,7 @@ type Config struct {
 	OutputFormat  string // json, text, html
 	OutputPath    string
 	Verbose       bool
+	Workers       int    // Concurrent detection workers, 0 = GOMAXPROCS
+	ConfigPath    string // Marker config file, "" = .techdebt in the scanned directory
+	NoSuppress    bool   // Disregard techdebt: directives and score every item
 }
@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"tech-debt-collector/internal/detector"
+	"tech-debt-collector/internal/models"
+	"tech-debt-collector/internal/scanner"
+)
+
+// Pipeline streams files from a Scanner through a pool of Detector workers
+type Pipeline struct {
+	Scanner  *scanner.Scanner
+	Detector *detector.Detector
+	Workers  int
+
+	// OnError is called for every file that could not be scanned.
+	// It always runs on the collector goroutine, so it need not be thread-safe.
+	OnError func(path string, err error)
+
+	// FilesScanned is the number of files processed by the last Run
+	FilesScanned int
+}
+
+// job is one file handed to a worker, tagged with its position in the walk
+type job struct {
+	seq  int
+	file scanner.File
+}
+
+// result carries a worker's findings back to the collector
+type result struct {
+	seq   int
+	path  string
+	items []models.DebtItem
+	err   error
+}
+
+// New creates a pipeline; workers <= 0 defaults to GOMAXPROCS
+func New(s *scanner.Scanner, d *detector.Detector, workers int) *Pipeline {
+	return &Pipeline{
+		Scanner:  s,
+		Detector: d,
+		Workers:  workers,
+	}
+}
+
+// Run walks the repository, detects debt concurrently and returns all items
+// in walk order, so the output does not depend on the number of workers.
+// Items from analyses spanning files, ordered by path, come last.
+func (p *Pipeline) Run(ctx context.Context) ([]models.DebtItem, error) {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan job, workers)
+	results := make(chan result, workers)
+	startedAt := time.Now()
+
+	// Files are collected for the analyses spanning files until Finish
+	p.Detector.Begin()
+
+	// Stage 1: walker
+	var walkErr error
+	go func() {
+		defer close(jobs)
+		seq := 0
+		walkErr = p.Scanner.Walk(runCtx, func(f scanner.File) error {
+			select {
+			case jobs <- job{seq: seq, file: f}:
+				seq++
+				return nil
+			case <-runCtx.Done():
+				return runCtx.Err()
+			}
+		})
+	}()
+
+	// Stage 2: detection workers
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res := result{seq: j.seq, path: j.file.Path}
+				if runCtx.Err() == nil {
+					res.items, res.err = p.detect(j.file)
+				}
+				select {
+				case results <- res:
+				case <-runCtx.Done():
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Stage 3: collector, re-sequencing results into walk order
+	var allItems []models.DebtItem
+	pending := make(map[int]result)
+	next := 0
+	p.FilesScanned = 0
+
+	for res := range results {
+		pending[res.seq] = res
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			p.FilesScanned++
+
+			if r.err != nil {
+				if p.OnError != nil {
+					p.OnError(r.path, r.err)
+				}
+				continue
+			}
+			for i := range r.items {
+				r.items[i].DetectedAt = startedAt
+			}
+			allItems = append(allItems, r.items...)
+		}
+	}
+
+	// Stage 4: analyses spanning files, once every file is seen
+	repoItems := p.Detector.Finish()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	for i := range repoItems {
+		repoItems[i].DetectedAt = startedAt
+	}
+	return append(allItems, repoItems...), nil
+}
+
+// detect runs the detector on one file, reading it from the scanner's file system
+func (p *Pipeline) detect(f scanner.File) ([]models.DebtItem, error) {
+	file, err := p.Scanner.Open(f)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return p.Detector.DetectInReaderAs(f.Path, f.Language, file, p.Scanner.GetFileImportance(f.Path))
+}
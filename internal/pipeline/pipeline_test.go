@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tech-debt-collector/internal/detector"
+	"tech-debt-collector/internal/scanner"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeRepo(t *testing.T, files int) string {
+	root := t.TempDir()
+	for i := 0; i < files; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%d", i%7))
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+		content := fmt.Sprintf("// Package p is a fixture.\npackage p\n// TODO: item %d\n// FIXME: bug %d\n// HACK TODO: both %d\n", i, i, i)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.go", i)), []byte(content), 0644))
+	}
+	return root
+}
+
+func TestPipelineDeterministicAcrossWorkerCounts(t *testing.T) {
+	root := writeRepo(t, 120)
+	s := scanner.NewScanner(root, nil, nil, true)
+	d := detector.NewDetector()
+
+	// The last run repeats the one before it
+	var outputs []string
+	for _, workers := range []int{1, 3, 16, 16} {
+		p := New(s, d, workers)
+		items, err := p.Run(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 120, p.FilesScanned)
+		assert.Len(t, items, 120*4)
+
+		for i := range items {
+			assert.NotEmpty(t, items[i].ID)
+			items[i].DetectedAt = time.Time{}
+		}
+		data, err := json.Marshal(items)
+		assert.NoError(t, err)
+		outputs = append(outputs, string(data))
+	}
+
+	assert.Equal(t, outputs[0], outputs[1])
+	assert.Equal(t, outputs[0], outputs[2])
+	assert.Equal(t, outputs[2], outputs[3])
+}
+
+func TestPipelineCancellation(t *testing.T) {
+	root := writeRepo(t, 50)
+	p := New(scanner.NewScanner(root, nil, nil, true), detector.NewDetector(), 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	items, err := p.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, items)
+}
+
+func TestPipelineReportsUnreadableFiles(t *testing.T) {
+	root := writeRepo(t, 3)
+	assert.NoError(t, os.Symlink(filepath.Join(root, "missing.go"), filepath.Join(root, "dangling.go")))
+
+	p := New(scanner.NewScanner(root, nil, nil, true), detector.NewDetector(), 2)
+	var failed []string
+	p.OnError = func(path string, err error) {
+		failed = append(failed, filepath.Base(path))
+	}
+
+	items, err := p.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, items, 3*4)
+	assert.Equal(t, []string{"dangling.go"}, failed)
+}
@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// ArchiveSeparator joins an archive path and the name of an entry inside it,
+// e.g. "release.zip!/src/main.go"
+const ArchiveSeparator = "!/"
+
+// Archive kinds recognised by ArchiveKind
+const (
+	ArchiveZip   = "zip"
+	ArchiveTar   = "tar"
+	ArchiveTarGz = "tar.gz"
+)
+
+// ArchiveKind sniffs the file at filePath and returns its archive kind,
+// or "" if it is not a supported archive. Go module zips are plain zips.
+func ArchiveKind(filePath string) string {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return ArchiveZip
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return ArchiveTarGz
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return ArchiveTar
+	}
+	return ""
+}
+
+// walkArchive streams the entries of the archive at RootPath. Entry content
+// is read up front, so files can be handed to workers after the walk moves on.
+func (s *Scanner) walkArchive(ctx context.Context, kind string, fn func(File) error) error {
+	switch kind {
+	case ArchiveZip:
+		return s.walkZip(ctx, fn)
+	default:
+		return s.walkTar(ctx, kind, fn)
+	}
+}
+
+// walkZip walks a zip through its fs.FS view, so ignore files inside it apply
+func (s *Scanner) walkZip(ctx context.Context, fn func(File) error) error {
+	zr, err := zip.OpenReader(s.RootPath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	return s.walkFS(ctx, zr, s.archivePath, func(f File) error {
+		data, err := fs.ReadFile(zr, f.Name)
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		f.data = data
+		return fn(f)
+	})
+}
+
+// walkTar streams a (possibly gzipped) tarball entry by entry. Entries come in
+// archive order, so ignore files inside the tarball are not honoured.
+func (s *Scanner) walkTar(ctx context.Context, kind string, fn func(File) error) error {
+	f, err := os.Open(s.RootPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	if kind == ArchiveTarGz {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if !fs.ValidPath(name) || !s.includeEntry(name) {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		language, ok := s.accept(name, s.archivePath(name), data)
+		if !ok {
+			continue
+		}
+		f := File{Path: s.archivePath(name), Name: name, Language: language, data: data}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+}
+
+// includeEntry applies the hidden, exclude and extension rules to an entry
+// whose parent directories were never visited by a walk. The language check
+// happens once the entry's content has been read.
+func (s *Scanner) includeEntry(name string) bool {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		if s.SkipHiddenDirs && strings.HasPrefix(part, ".") {
+			return false
+		}
+		if i < len(parts)-1 && s.ExcludeDirs[part] {
+			return false
+		}
+	}
+
+	if s.OnlyFiles != nil && !s.OnlyFiles[name] {
+		return false
+	}
+	return s.mayInclude(name)
+}
+
+// archivePath is the reported path of an archive entry
+func (s *Scanner) archivePath(name string) string {
+	return s.RootPath + ArchiveSeparator + name
+}
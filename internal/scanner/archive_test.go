@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var archiveEntries = map[string]string{
+	"mod@v1.0.0/main.go":             "// TODO: ship it",
+	"mod@v1.0.0/README.md":           "docs",
+	"mod@v1.0.0/vendor/dep/dep.go":   "// TODO: vendored",
+	"mod@v1.0.0/.cache/tmp.go":       "// TODO: hidden",
+	"mod@v1.0.0/internal/handler.go": "// FIXME: handler",
+}
+
+func writeTarGz(t *testing.T, path string) {
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "mod@v1.0.0/", Typeflag: tar.TypeDir, Mode: 0755}))
+	for name, content := range archiveEntries {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+}
+
+func writeZip(t *testing.T, path string) {
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range archiveEntries {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+}
+
+func scanArchive(t *testing.T, archive string) map[string]string {
+	s := NewScanner(archive, []string{"vendor"}, nil, true)
+	found := make(map[string]string)
+	err := s.Walk(context.Background(), func(f File) error {
+		rc, err := s.Open(f)
+		assert.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		found[f.Path] = string(data)
+		return rc.Close()
+	})
+	assert.NoError(t, err)
+	return found
+}
+
+func TestScanArchives(t *testing.T) {
+	dir := t.TempDir()
+	tgz := filepath.Join(dir, "release.tar.gz")
+	zipped := filepath.Join(dir, "release.zip")
+	writeTarGz(t, tgz)
+	writeZip(t, zipped)
+
+	assert.Equal(t, ArchiveTarGz, ArchiveKind(tgz))
+	assert.Equal(t, ArchiveZip, ArchiveKind(zipped))
+	assert.Equal(t, "", ArchiveKind(filepath.Join(dir, "missing.zip")))
+
+	for _, archive := range []string{tgz, zipped} {
+		found := scanArchive(t, archive)
+		assert.Equal(t, map[string]string{
+			archive + "!/mod@v1.0.0/main.go":             "// TODO: ship it",
+			archive + "!/mod@v1.0.0/internal/handler.go": "// FIXME: handler",
+		}, found, archive)
+	}
+}
+
+func TestScanSingleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "one.go")
+	assert.NoError(t, os.WriteFile(path, []byte("package one"), 0644))
+
+	files, err := NewScanner(path, nil, nil, true).ScanFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{path}, files)
+}
@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Reasons a matching file is skipped, as reported in models.SkippedFile
+const (
+	SkipGenerated = "generated"
+	SkipMinified  = "minified"
+	SkipBinary    = "binary"
+)
+
+// sniffSize is how much of each file is read to classify it
+const sniffSize = 8 * 1024
+
+// minifiedLineLength is the average line length above which a file is
+// considered minified
+const minifiedLineLength = 250
+
+var (
+	// generatedHeader matches well-known "do not edit" banners, such as Go's
+	// "// Code generated ... DO NOT EDIT." and protoc's "Generated by the
+	// protocol buffer compiler", in the first lines of a file
+	generatedHeader = regexp.MustCompile(`(?i)(^|\n)[^\n]{0,20}(code generated .* do not edit|@generated\b|generated by the protocol buffer compiler|auto-?generated .*do not (edit|modify)|this file (is|was) (auto-?)?generated)`)
+
+	generatedSuffixes = []string{".pb.go", ".pb.gw.go", "_pb2.py", "_pb2_grpc.py", ".pb.h", ".pb.cc", "_generated.go", ".gen.go"}
+	minifiedSuffixes  = []string{".min.js", ".min.css", ".min.mjs", "-min.js", ".bundle.js"}
+)
+
+// Classify reports why a file should not be treated as hand-written source:
+// SkipBinary, SkipGenerated, SkipMinified, or "" if it looks hand-written.
+// head is the start of the file's content.
+func Classify(name string, head []byte) string {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return SkipBinary
+	}
+
+	base := strings.ToLower(path.Base(name))
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return SkipGenerated
+		}
+	}
+	if generatedHeader.Match(leadingLines(head, 10)) {
+		return SkipGenerated
+	}
+
+	for _, suffix := range minifiedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return SkipMinified
+		}
+	}
+	if isMinified(head) {
+		return SkipMinified
+	}
+
+	return ""
+}
+
+// leadingLines returns the first n lines of data
+func leadingLines(data []byte, n int) []byte {
+	end := 0
+	for i := 0; i < n; i++ {
+		j := bytes.IndexByte(data[end:], '\n')
+		if j < 0 {
+			return data
+		}
+		end += j + 1
+	}
+	return data[:end]
+}
+
+// isMinified flags content whose lines are, on average, far longer than
+// anything written by hand. Short files are never considered minified.
+func isMinified(head []byte) bool {
+	if len(head) < 1024 {
+		return false
+	}
+	lines := bytes.Count(head, []byte("\n")) + 1
+	return len(head)/lines > minifiedLineLength
+}
@@ -0,0 +1,62 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"tech-debt-collector/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	minified := strings.Repeat("var a=function(b){return b+1};", 100)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"main.go", "package main\n\nfunc main() {}\n", ""},
+		{"api.pb.go", "package api\n", SkipGenerated},
+		{"types.go", "// Code generated by stringer -type=Kind; DO NOT EDIT.\n\npackage x\n", SkipGenerated},
+		{"schema.py", "# Generated by the protocol buffer compiler.  DO NOT EDIT!\n", SkipGenerated},
+		{"Foo.java", "/*\n * @generated by tool\n */\nclass Foo {}\n", SkipGenerated},
+		{"late.go", strings.Repeat("// hand written\n", 20) + "// Code generated by x. DO NOT EDIT.\n", ""},
+		{"app.min.js", "short", SkipMinified},
+		{"bundle.js", minified, SkipMinified},
+		{"long.js", strings.Repeat("const x = 1;\n", 200), ""},
+		{"image.h", "GIF89a\x00\x01\x02", SkipBinary},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Classify(tt.name, []byte(tt.content)), tt.name)
+	}
+}
+
+func TestScanSkipsGeneratedFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go":      {Data: []byte("package main\n")},
+		"enum_gen.go":  {Data: []byte("// Code generated by stringer; DO NOT EDIT.\npackage main\n")},
+		"web/app.js":   {Data: []byte(strings.Repeat("x", 5000))},
+		"include/db.h": {Data: []byte("\x7fELF\x00\x00")},
+		"README.md":    {Data: []byte("\x00 not scanned anyway")},
+	}
+
+	s := NewScannerFS(fsys, nil, nil, true)
+	files, err := s.ScanFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, files)
+	assert.Equal(t, []models.SkippedFile{
+		{FilePath: "enum_gen.go", Reason: SkipGenerated},
+		{FilePath: "include/db.h", Reason: SkipBinary},
+		{FilePath: "web/app.js", Reason: SkipMinified},
+	}, s.Skipped)
+
+	s.IncludeGenerated = true
+	files, err = s.ScanFiles()
+	assert.NoError(t, err)
+	assert.Len(t, files, 4)
+	assert.Empty(t, s.Skipped)
+}
@@ -0,0 +1,188 @@
+package scanner
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileNames lists the per-directory ignore files honoured while scanning.
+// Rules from later files override earlier ones in the same directory.
+var IgnoreFileNames = []string{".gitignore", ".ignore"}
+
+// ignoreRule is a single compiled gitignore pattern
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreList holds the rules read from the ignore files of one directory
+type ignoreList struct {
+	rules []ignoreRule
+}
+
+// ignoreMatcher applies ignore lists hierarchically, keyed by the
+// slash-separated directory (relative to the scan root) they were found in
+type ignoreMatcher struct {
+	lists map[string]*ignoreList
+}
+
+func newIgnoreMatcher() *ignoreMatcher {
+	return &ignoreMatcher{lists: make(map[string]*ignoreList)}
+}
+
+// add parses ignore rules from r and attaches them to dir
+func (m *ignoreMatcher) add(dir string, r io.Reader) error {
+	list := m.lists[dir]
+	if list == nil {
+		list = &ignoreList{}
+	}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if rule, ok := parseIgnoreLine(sc.Text()); ok {
+			list.rules = append(list.rules, rule)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	if len(list.rules) > 0 {
+		m.lists[dir] = list
+	}
+	return nil
+}
+
+// Ignored reports whether rel (slash-separated, relative to the scan root)
+// is excluded. Rules in deeper directories take precedence over their
+// parents and, within a directory, the last matching rule wins.
+func (m *ignoreMatcher) Ignored(rel string, isDir bool) bool {
+	if len(m.lists) == 0 {
+		return false
+	}
+
+	ignored := false
+	dir := ""
+	for {
+		if list, ok := m.lists[dir]; ok {
+			sub := rel
+			if dir != "" {
+				sub = strings.TrimPrefix(rel, dir+"/")
+			}
+			for _, rule := range list.rules {
+				if rule.dirOnly && !isDir {
+					continue
+				}
+				if rule.pattern.MatchString(sub) {
+					ignored = !rule.negate
+				}
+			}
+		}
+
+		// Descend one level towards rel
+		rest := strings.TrimPrefix(rel, dir)
+		rest = strings.TrimPrefix(rest, "/")
+		i := strings.Index(rest, "/")
+		if i < 0 {
+			break
+		}
+		dir = path.Join(dir, rest[:i])
+	}
+
+	return ignored
+}
+
+// parseIgnoreLine compiles one line of a gitignore file
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	line = trimTrailingSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A slash anywhere but the end anchors the pattern to the ignore file's directory
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored && !strings.HasPrefix(line, "**/") {
+		expr = "(?:.*/)?" + expr
+	}
+
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.pattern = re
+	return rule, true
+}
+
+// globToRegexp translates gitignore glob syntax into a regular expression
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			// Leading or middle "**/" matches zero or more directories
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**") && i+2 == len(glob) && (i == 0 || glob[i-1] == '/'):
+			// Trailing "/**" matches everything inside
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
+
+// trimTrailingSpace strips unescaped trailing spaces
+func trimTrailingSpace(line string) string {
+	line = strings.TrimRight(line, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
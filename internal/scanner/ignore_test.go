@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnorePatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "logs/debug.log", false, true},
+		{"*.log", "debug.go", false, false},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"out/", "out", true, true},
+		{"out/", "out", false, false},
+		{"out/", "pkg/out", true, true},
+		{"doc/*.txt", "doc/notes.txt", false, true},
+		{"doc/*.txt", "doc/api/notes.txt", false, false},
+		{"**/gen", "a/b/gen", true, true},
+		{"**/gen", "gen", true, true},
+		{"assets/**", "assets/img/logo.png", false, true},
+		{"assets/**", "assets", true, false},
+		{"a/**/b", "a/b", true, true},
+		{"a/**/b", "a/x/y/b", true, true},
+		{"file?.go", "file1.go", false, true},
+		{"file[0-9].go", "filex.go", false, false},
+		{`\#notes`, "#notes", false, true},
+		{"# comment", "# comment", false, false},
+	}
+
+	for _, tt := range tests {
+		m := newIgnoreMatcher()
+		assert.NoError(t, m.add("", strings.NewReader(tt.pattern)))
+		assert.Equal(t, tt.ignored, m.Ignored(tt.path, tt.isDir), "%q vs %q", tt.pattern, tt.path)
+	}
+}
+
+func TestIgnoreNegationAndNesting(t *testing.T) {
+	m := newIgnoreMatcher()
+	assert.NoError(t, m.add("", strings.NewReader("*.gen.go\n!keep.gen.go\n")))
+	assert.NoError(t, m.add("pkg", strings.NewReader("!*.gen.go\n/local.go\n")))
+
+	assert.True(t, m.Ignored("api.gen.go", false))
+	assert.False(t, m.Ignored("keep.gen.go", false))
+	assert.False(t, m.Ignored("pkg/api.gen.go", false))
+	assert.True(t, m.Ignored("pkg/local.go", false))
+	assert.False(t, m.Ignored("local.go", false))
+	assert.False(t, m.Ignored("pkg/sub/local.go", false))
+}
+
+func TestScanFilesRespectsIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":           "dist/\n*_mock.go\n",
+		"main.go":              "package main",
+		"api_mock.go":          "package main",
+		"dist/bundle.js":       "",
+		"pkg/.ignore":          "scratch.go\n",
+		"pkg/scratch.go":       "package pkg",
+		"pkg/pkg.go":           "package pkg",
+		"pkg/gen/.gitignore":   "!*_mock.go\n",
+		"pkg/gen/keep_mock.go": "package gen",
+		"pkg/gen/other.go":     "package gen",
+		"pkg/gen/scratch.go":   "package gen",
+		"tools/dist/tool.go":   "package dist",
+		"tools/dist/tool2.go":  "package dist",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	s := NewScanner(root, nil, nil, true)
+	found, err := s.ScanFiles()
+	assert.NoError(t, err)
+
+	var rel []string
+	for _, f := range found {
+		r, _ := filepath.Rel(root, f)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	sort.Strings(rel)
+	assert.Equal(t, []string{"main.go", "pkg/gen/keep_mock.go", "pkg/gen/other.go", "pkg/pkg.go"}, rel)
+
+	s.UseIgnoreFiles = false
+	found, err = s.ScanFiles()
+	assert.NoError(t, err)
+	assert.Len(t, found, 10)
+}
@@ -1,17 +1,55 @@
 package scanner
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"tech-debt-collector/internal/deps"
+	"tech-debt-collector/internal/lang"
+	"tech-debt-collector/internal/models"
 )
 
 // Scanner scans a repository for source files
 type Scanner struct {
 	RootPath          string
+	FS                fs.FS // Walked instead of RootPath on disk when set
 	ExcludeDirs       map[string]bool
 	IncludeExtensions map[string]bool
+	IncludeLanguages  map[string]bool // Also scan files detected as these languages; only files without an extension are sniffed
+	IncludeManifests  bool            // Also scan dependency manifests such as go.mod and package.json
 	SkipHiddenDirs    bool
+	UseIgnoreFiles    bool // Honour .gitignore/.ignore files while walking
+	IncludeGenerated  bool // Also scan generated, minified and binary files
+
+	// Skipped lists matching files left out by the last walk because they
+	// look generated, minified or binary
+	Skipped []models.SkippedFile
+
+	// OnlyFiles restricts the walk to these root-relative, slash-separated
+	// paths (e.g. files changed since a git ref). Nil means no restriction.
+	OnlyFiles map[string]bool
+}
+
+// DefaultLanguages are scanned when no extensions are given
+var DefaultLanguages = []string{
+	"go", "python", "javascript", "typescript", "java", "c", "cpp", "rust",
+	"ruby", "php", "shell", "makefile", "dockerfile", "groovy", "yaml",
+}
+
+// File is a source file found while walking the repository
+type File struct {
+	Path string // Path as reported to users
+	Name string // Slash-separated name within the scanned file system
+
+	Language string // Language ID from lang.Detect, "" if unknown
+
+	data []byte // Content read during the walk, for archive entries and single files
 }
 
 // NewScanner creates a new repository scanner
@@ -26,8 +64,14 @@ func NewScanner(rootPath string, excludeDirs, includeExtensions []string, skipHi
 		extMap[ext] = true
 	}
 
-	// Default extensions if none specified
+	// Default extensions if none specified, plus extensionless
+	// files recognised by name, shebang or modeline, and manifests
+	langMap := make(map[string]bool)
 	if len(extMap) == 0 {
+		for _, id := range DefaultLanguages {
+			langMap[id] = true
+		}
+
 		extMap[".go"] = true
 		extMap[".py"] = true
 		extMap[".js"] = true
@@ -47,45 +91,223 @@ func NewScanner(rootPath string, excludeDirs, includeExtensions []string, skipHi
 		RootPath:          rootPath,
 		ExcludeDirs:       excludeMap,
 		IncludeExtensions: extMap,
+		IncludeLanguages:  langMap,
+		IncludeManifests:  len(includeExtensions) == 0,
 		SkipHiddenDirs:    skipHidden,
+		UseIgnoreFiles:    true,
 	}
 }
 
+// NewScannerFS creates a scanner over an arbitrary file system, such as an
+// fstest.MapFS, a zip.Reader or an embed.FS. Reported paths are fsys names.
+func NewScannerFS(fsys fs.FS, excludeDirs, includeExtensions []string, skipHidden bool) *Scanner {
+	s := NewScanner("", excludeDirs, includeExtensions, skipHidden)
+	s.FS = fsys
+	return s
+}
+
 // ScanFiles recursively scans for source files
 func (s *Scanner) ScanFiles() ([]string, error) {
 	var files []string
 
-	err := filepath.Walk(s.RootPath, func(path string, info os.FileInfo, err error) error {
+	err := s.Walk(context.Background(), func(f File) error {
+		files = append(files, f.Path)
+		return nil
+	})
+
+	return files, err
+}
+
+// Walk streams source files to fn as they are found: in lexical order for
+// directories, in archive order for tarballs. If RootPath is a zip or tar
+// archive its entries are walked without extracting it.
+// Walking stops at the first error returned by fn or when ctx is done.
+func (s *Scanner) Walk(ctx context.Context, fn func(File) error) error {
+	s.Skipped = nil
+	if s.FS != nil {
+		return s.walkFS(ctx, s.FS, s.displayPath, fn)
+	}
+
+	info, err := os.Stat(s.RootPath)
+	if err != nil {
+		return nil // Nothing to scan
+	}
+	if !info.IsDir() {
+		if kind := ArchiveKind(s.RootPath); kind != "" {
+			return s.walkArchive(ctx, kind, fn)
+		}
+		return s.walkSingleFile(fn)
+	}
+
+	return s.walkFS(ctx, s.fileSystem(), s.displayPath, fn)
+}
+
+// walkSingleFile handles a RootPath that names one source file
+func (s *Scanner) walkSingleFile(fn func(File) error) error {
+	name := filepath.Base(s.RootPath)
+	if !s.mayInclude(name) {
+		return nil
+	}
+	data, err := os.ReadFile(s.RootPath)
+	if err != nil {
+		return nil // Skip on error
+	}
+	language, ok := s.accept(name, s.RootPath, data)
+	if !ok {
+		return nil
+	}
+	return fn(File{Path: s.RootPath, Name: name, Language: language, data: data})
+}
+
+// mayInclude reports whether name could be scanned, before reading it. The
+// language of a file with an extension is known from its name; only files
+// without one are read, for a shebang or modeline.
+func (s *Scanner) mayInclude(name string) bool {
+	if s.IncludeExtensions[path.Ext(name)] || s.includeManifest(name) {
+		return true
+	}
+	if len(s.IncludeLanguages) == 0 {
+		return false
+	}
+	return path.Ext(name) == "" || s.IncludeLanguages[lang.Detect(name, nil)]
+}
+
+// includeManifest reports whether name is a dependency manifest to scan
+func (s *Scanner) includeManifest(name string) bool {
+	return s.IncludeManifests && deps.IsManifest(name)
+}
+
+// accept detects the language of a file and decides whether it is scanned.
+// Matching files that look generated, minified or binary are recorded in
+// Skipped instead, unless IncludeGenerated is set.
+func (s *Scanner) accept(name, displayPath string, head []byte) (string, bool) {
+	language := lang.Detect(name, lang.Head(head))
+	if !s.IncludeExtensions[path.Ext(name)] && !s.IncludeLanguages[language] && !s.includeManifest(name) {
+		return language, false
+	}
+
+	if !s.IncludeGenerated {
+		if reason := Classify(name, head); reason != "" {
+			s.Skipped = append(s.Skipped, models.SkippedFile{FilePath: displayPath, Reason: reason})
+			return language, false
+		}
+	}
+	return language, true
+}
+
+// readHead reads the leading bytes used to detect and classify a file
+func readHead(fsys fs.FS, name string) []byte {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffSize)
+	n, _ := io.ReadFull(f, head)
+	return head[:n]
+}
+
+// walkFS walks a directory tree, applying the hidden, ignore, exclude and
+// extension rules. display maps names to the paths reported to users.
+func (s *Scanner) walkFS(ctx context.Context, fsys fs.FS, display func(string) string, fn func(File) error) error {
+	ignores := newIgnoreMatcher()
+
+	return fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			return nil // Skip on error
 		}
 
+		// The root itself is always scanned, even when given as "."
+		isRoot := name == "."
+
 		// Skip hidden files/dirs
-		if s.SkipHiddenDirs && strings.HasPrefix(info.Name(), ".") {
-			if info.IsDir() {
-				return filepath.SkipDir
+		if !isRoot && s.SkipHiddenDirs && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		// Skip paths excluded by .gitignore/.ignore rules
+		if !isRoot && s.UseIgnoreFiles && ignores.Ignored(name, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
 			}
 			return nil
 		}
 
 		// Skip excluded directories
-		if info.IsDir() {
-			if s.ExcludeDirs[info.Name()] {
-				return filepath.SkipDir
+		if d.IsDir() {
+			if !isRoot && s.ExcludeDirs[d.Name()] {
+				return fs.SkipDir
 			}
+			if s.UseIgnoreFiles {
+				s.loadIgnoreFiles(fsys, ignores, name)
+			}
+			return nil
+		}
+
+		// Restrict to an explicit file list, if any
+		if s.OnlyFiles != nil && !s.OnlyFiles[name] {
 			return nil
 		}
 
-		// Check if file extension is in include list
-		ext := filepath.Ext(path)
-		if s.IncludeExtensions[ext] {
-			files = append(files, path)
+		// Check if the file's extension or language is in an include list
+		if !s.mayInclude(name) {
+			return nil
+		}
+		if language, ok := s.accept(name, display(name), readHead(fsys, name)); ok {
+			return fn(File{Path: display(name), Name: name, Language: language})
 		}
 
 		return nil
 	})
+}
 
-	return files, err
+// Open opens a file found by Walk
+func (s *Scanner) Open(f File) (io.ReadCloser, error) {
+	if f.data != nil {
+		return io.NopCloser(bytes.NewReader(f.data)), nil
+	}
+	return s.fileSystem().Open(f.Name)
+}
+
+// fileSystem returns the file system to walk, defaulting to RootPath on disk
+func (s *Scanner) fileSystem() fs.FS {
+	if s.FS != nil {
+		return s.FS
+	}
+	return os.DirFS(s.RootPath)
+}
+
+// displayPath maps a file system name to the path reported to users.
+// On-disk scans keep paths rooted at RootPath, as filepath.Walk would.
+func (s *Scanner) displayPath(name string) string {
+	if s.FS != nil {
+		return name
+	}
+	return filepath.Join(s.RootPath, filepath.FromSlash(name))
+}
+
+// loadIgnoreFiles reads the ignore files of dir into the matcher
+func (s *Scanner) loadIgnoreFiles(fsys fs.FS, ignores *ignoreMatcher, dir string) {
+	rel := dir
+	if rel == "." {
+		rel = ""
+	}
+
+	for _, name := range IgnoreFileNames {
+		f, err := fsys.Open(path.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		_ = ignores.add(rel, f) // A malformed ignore file is not fatal
+		f.Close()
+	}
 }
 
 // GetFileImportance scores file importance (1-5)
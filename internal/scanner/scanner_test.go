@@ -0,0 +1,134 @@
+package scanner
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewScannerFSWithMapFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.go":             {Data: []byte("package main")},
+		"README.md":           {Data: []byte("docs")},
+		"node_modules/dep.js": {Data: []byte("")},
+		".hidden/x.go":        {Data: []byte("")},
+		"lib/util.py":         {Data: []byte("")},
+		".gitignore":          {Data: []byte("lib/\n")},
+	}
+
+	s := NewScannerFS(fsys, []string{"node_modules"}, nil, true)
+	files, err := s.ScanFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, files)
+
+	s.UseIgnoreFiles = false
+	files, err = s.ScanFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"lib/util.py", "main.go"}, files)
+}
+
+func TestNewScannerFSWithZipReader(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"src/app.go", "src/app.txt", "web/index.js"} {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte("// TODO: zipped"))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	s := NewScannerFS(zr, nil, []string{".go", ".js"}, true)
+	files, err := s.ScanFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"src/app.go", "web/index.js"}, files)
+
+	f, err := s.Open(File{Path: "src/app.go", Name: "src/app.go"})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+}
+
+func TestScanFilesDetectsLanguages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Makefile":         {Data: []byte("all:\n\t# TODO: lint\n")},
+		"Dockerfile":       {Data: []byte("FROM alpine\n")},
+		"Jenkinsfile":      {Data: []byte("pipeline {}\n")},
+		"bin/deploy":       {Data: []byte("#!/usr/bin/env bash\n")},
+		"bin/gen":          {Data: []byte("#!/usr/bin/python3\n")},
+		"bin/blob":         {Data: []byte("\x00\x01")},
+		"main.go":          {Data: []byte("package main\n")},
+		"notes.txt":        {Data: []byte("plain text\n")},
+		"legacy/tool.txt":  {Data: []byte("# vim: set ft=python:\n")},
+		"legacy/tool":      {Data: []byte("# vim: set ft=python:\n")},
+		"go.mod":           {Data: []byte("module example.com/a\n")},
+		"web/package.json": {Data: []byte("{}\n")},
+	}
+
+	languages := make(map[string]string)
+	err := NewScannerFS(fsys, nil, nil, true).Walk(context.Background(), func(f File) error {
+		languages[f.Path] = f.Language
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Makefile":         "makefile",
+		"Dockerfile":       "dockerfile",
+		"Jenkinsfile":      "groovy",
+		"bin/deploy":       "shell",
+		"bin/gen":          "python",
+		"main.go":          "go",
+		"legacy/tool":      "python",
+		"go.mod":           "",
+		"web/package.json": "",
+	}, languages, "manifests are scanned by name, and only files without an extension are sniffed")
+
+	// Explicit extensions only scan by name, but still record the language
+	languages = make(map[string]string)
+	err = NewScannerFS(fsys, nil, []string{".txt"}, true).Walk(context.Background(), func(f File) error {
+		languages[f.Path] = f.Language
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"notes.txt": "", "legacy/tool.txt": "python"}, languages)
+}
+
+// openCounter is a file system that records which files are opened
+type openCounter struct {
+	fstest.MapFS
+	opened []string
+}
+
+func (o *openCounter) Open(name string) (fs.File, error) {
+	o.opened = append(o.opened, name)
+	return o.MapFS.Open(name)
+}
+
+func TestWalkOnlyReadsCandidates(t *testing.T) {
+	fsys := &openCounter{MapFS: fstest.MapFS{
+		"main.go":        {Data: []byte("package main\n")},
+		"logo.png":       {Data: []byte("\x89PNG")},
+		"notes.txt":      {Data: []byte("# vim: set ft=python:\n")},
+		"bin/deploy":     {Data: []byte("#!/bin/sh\n")},
+		"bin/data":       {Data: []byte("\x00\x01")},
+		"docs/README.md": {Data: []byte("# Docs\n")},
+	}}
+
+	s := NewScannerFS(fsys, nil, nil, true)
+	s.UseIgnoreFiles = false
+	var scanned []string
+	err := s.Walk(context.Background(), func(f File) error {
+		scanned = append(scanned, f.Path)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bin/deploy", "main.go"}, scanned)
+	assert.ElementsMatch(t, []string{"bin/data", "bin/deploy", "main.go"}, fsys.opened)
+}
@@ -1,6 +1,8 @@
 package scorer
 
 import (
+	"time"
+
 	"tech-debt-collector/internal/models"
 )
 
@@ -9,6 +11,9 @@ type Scorer struct {
 	SeverityWeight    float64
 	CriticalityWeight float64
 	FrequencyWeight   float64
+	OverdueWeight     float64            // Added when an item is past its due date
+	CategoryWeights   map[string]float64 // Risk multiplier per DebtItem.Category, default 1
+	Now               func() time.Time   // Clock used to decide what is overdue
 }
 
 // NewScorer creates a new risk scorer with default weights
@@ -17,6 +22,11 @@ func NewScorer() *Scorer {
 		SeverityWeight:    0.5,  // 50% from severity
 		CriticalityWeight: 0.35, // 35% from file criticality
 		FrequencyWeight:   0.15, // 15% from frequency
+		OverdueWeight:     0.2,  // Overdue items jump a priority band
+		CategoryWeights: map[string]float64{
+			models.CategoryTest: 1.25, // A skipped test leaves the code it covers unguarded
+		},
+		Now: time.Now,
 	}
 }
 
@@ -32,10 +42,23 @@ func (s *Scorer) ScoreItem(item *models.DebtItem) float64 {
 		(criticalityScore * s.CriticalityWeight) +
 		(frequencyScore * s.FrequencyWeight)
 
+	if s.Overdue(item) {
+		risk += s.OverdueWeight
+	}
+	if weight, ok := s.CategoryWeights[item.Category]; ok {
+		risk *= weight
+	}
+	risk = min(risk, 1)
+
 	// Scale to 0-100
 	return risk * 100
 }
 
+// Overdue reports whether the item's due date has passed
+func (s *Scorer) Overdue(item *models.DebtItem) bool {
+	return item.DueDate != nil && item.DueDate.Before(s.Now())
+}
+
 // ScoreAll calculates risk scores for all items
 func (s *Scorer) ScoreAll(items []models.DebtItem) []models.DebtItem {
 	for i := range items {
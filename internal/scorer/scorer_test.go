@@ -2,6 +2,7 @@ package scorer
 
 import (
 	"testing"
+	"time"
 
 	"tech-debt-collector/internal/models"
 
@@ -96,9 +97,39 @@ func TestScorerStats(t *testing.T) {
 		items[i].Risk = s.ScoreItem(&items[i])
 	}
 
-	critical, high, medium, low := s.GetStats(items)
+	_, high, medium, low := s.GetStats(items)
 
 	assert.Greater(t, high, 0)
 	assert.Greater(t, medium, 0)
 	assert.Greater(t, low, 0)
 }
+
+func TestScorerRaisesOverdueRisk(t *testing.T) {
+	s := NewScorer()
+	s.Now = func() time.Time { return time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC) }
+
+	past := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	future := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := models.DebtItem{Severity: 3, FileImportance: 3, Frequency: 1}
+	overdue, notDue := base, base
+	overdue.DueDate = &past
+	notDue.DueDate = &future
+
+	assert.True(t, s.Overdue(&overdue))
+	assert.False(t, s.Overdue(&notDue))
+	assert.Equal(t, s.ScoreItem(&base), s.ScoreItem(&notDue))
+	assert.InDelta(t, s.ScoreItem(&base)+20, s.ScoreItem(&overdue), 0.001)
+
+	worst := models.DebtItem{Severity: 5, FileImportance: 5, Frequency: 5, DueDate: &past}
+	assert.Equal(t, 100.0, s.ScoreItem(&worst))
+}
+
+func TestScorerWeightsCategories(t *testing.T) {
+	s := NewScorer()
+
+	comment := models.DebtItem{Severity: 3, FileImportance: 3, Frequency: 1, Category: models.CategoryComment}
+	skipped := comment
+	skipped.Category = models.CategoryTest
+
+	assert.InDelta(t, s.ScoreItem(&comment)*1.25, s.ScoreItem(&skipped), 0.001)
+}
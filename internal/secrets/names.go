@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"strings"
+	"unicode"
+)
+
+// credentialStems end the words of names that hold a secret, e.g.
+// DB_PASSWORD, clientSecret or csrftoken. A stem may also span two words,
+// as api and key do in OPENAI_API_KEY.
+var credentialStems = []string{
+	"password", "passwd", "pwd", "passphrase", "secret", "token",
+	"apikey", "accesskey", "privatekey", "secretkey", "credential", "credentials",
+}
+
+// referenceWords end the names of settings that locate or describe a secret
+// rather than hold it, e.g. PASSWORD_FILE, TOKEN_URL or secretName
+var referenceWords = map[string]bool{
+	"file": true, "path": true, "dir": true, "url": true, "uri": true, "endpoint": true,
+	"host": true, "port": true, "user": true, "username": true, "login": true,
+	"name": true, "id": true, "type": true, "kind": true, "header": true, "field": true,
+	"param": true, "prefix": true, "suffix": true, "env": true, "var": true,
+	"len": true, "length": true, "count": true, "size": true, "min": true, "max": true,
+	"format": true, "pattern": true, "regex": true, "re": true, "policy": true,
+	"expiry": true, "expires": true, "ttl": true, "timeout": true, "label": true,
+	"hint": true, "prompt": true, "placeholder": true, "hash": true,
+}
+
+// IsCredentialName reports whether a variable, key or field name says it
+// holds a password, token or key
+func IsCredentialName(name string) bool {
+	words := nameWords(name)
+	if len(words) == 0 || referenceWords[words[len(words)-1]] {
+		return false
+	}
+	for i, w := range words {
+		if w == "pass" || w == "pw" || hasStem(w) {
+			return true
+		}
+		if i+1 < len(words) && hasStem(w+words[i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasStem(word string) bool {
+	for _, stem := range credentialStems {
+		if strings.HasSuffix(word, stem) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameWords splits a name into lower-case words at punctuation and at
+// camel case boundaries: APIKey_file becomes api, key and file
+func nameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+			}
+			start = -1
+			continue
+		}
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = i
+			}
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
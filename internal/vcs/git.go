@@ -0,0 +1,193 @@
+package vcs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tech-debt-collector/internal/models"
+)
+
+// LineRange is an inclusive range of line numbers in the new version of a file
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// ChangeSet records which lines of which files were added or modified.
+// Paths are slash-separated and relative to the directory the diff ran in.
+type ChangeSet struct {
+	Files map[string][]LineRange
+}
+
+// ChangedSince diffs the working tree in dir against the merge base of ref
+// and HEAD, so only changes made on the current branch are reported
+func ChangedSince(ctx context.Context, dir, ref string) (*ChangeSet, error) {
+	out, err := runGit(ctx, dir, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	base := strings.TrimSpace(string(out))
+
+	return diff(ctx, dir, base)
+}
+
+// StagedChanges diffs the index in dir against HEAD
+func StagedChanges(ctx context.Context, dir string) (*ChangeSet, error) {
+	return diff(ctx, dir, "--cached")
+}
+
+// diff runs a zero-context diff and parses its hunks
+func diff(ctx context.Context, dir string, args ...string) (*ChangeSet, error) {
+	gitArgs := []string{
+		"-c", "core.quotePath=false",
+		"diff", "--relative", "--unified=0", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", "--diff-filter=ACMR",
+	}
+	out, err := runGit(ctx, dir, append(gitArgs, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDiff(out)
+}
+
+// ParseDiff extracts added/modified line ranges from unified diff output
+func ParseDiff(data []byte) (*ChangeSet, error) {
+	cs := &ChangeSet{Files: make(map[string][]LineRange)}
+	current := ""
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			current = parseDiffPath(strings.TrimPrefix(line, "+++ "))
+			if current != "" {
+				if _, ok := cs.Files[current]; !ok {
+					cs.Files[current] = nil
+				}
+			}
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			r, ok, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				cs.Files[current] = append(cs.Files[current], r)
+			}
+		}
+	}
+
+	return cs, sc.Err()
+}
+
+// parseDiffPath strips the destination prefix, returning "" for deletions
+func parseDiffPath(p string) string {
+	if p == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(p, `"`) {
+		if unquoted, err := strconv.Unquote(p); err == nil {
+			p = unquoted
+		}
+	}
+	return strings.TrimPrefix(p, "b/")
+}
+
+// parseHunkHeader reads the new-file range of "@@ -a,b +c,d @@".
+// Pure deletions (d == 0) report ok == false.
+func parseHunkHeader(line string) (LineRange, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return LineRange{}, false, fmt.Errorf("malformed hunk header: %q", line)
+	}
+
+	spec := strings.TrimPrefix(fields[2], "+")
+	start, count := spec, "1"
+	if i := strings.IndexByte(spec, ','); i >= 0 {
+		start, count = spec[:i], spec[i+1:]
+	}
+
+	s, err := strconv.Atoi(start)
+	if err != nil {
+		return LineRange{}, false, fmt.Errorf("malformed hunk header: %q", line)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return LineRange{}, false, fmt.Errorf("malformed hunk header: %q", line)
+	}
+	if n == 0 {
+		return LineRange{}, false, nil
+	}
+
+	return LineRange{Start: s, End: s + n - 1}, true, nil
+}
+
+// Paths returns the changed files in sorted order
+func (c *ChangeSet) Paths() []string {
+	paths := make([]string, 0, len(c.Files))
+	for p := range c.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// FileSet returns the changed files as a lookup set
+func (c *ChangeSet) FileSet() map[string]bool {
+	set := make(map[string]bool, len(c.Files))
+	for p := range c.Files {
+		set[p] = true
+	}
+	return set
+}
+
+// Touches reports whether line of path was added or modified
+func (c *ChangeSet) Touches(path string, line int) bool {
+	for _, r := range c.Files[path] {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterItems keeps only items with any of their lines changed. root is the
+// directory the diff ran in, used to relativise each item's FilePath.
+func (c *ChangeSet) FilterItems(root string, items []models.DebtItem) []models.DebtItem {
+	var kept []models.DebtItem
+	for _, item := range items {
+		rel, err := filepath.Rel(root, item.FilePath)
+		if err != nil {
+			continue
+		}
+		for line := item.LineNumber; line <= max(item.LineNumber, item.EndLine); line++ {
+			if c.Touches(filepath.ToSlash(rel), line) {
+				kept = append(kept, item)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// runGit executes git in dir and returns its stdout
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,149 @@
+package vcs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"tech-debt-collector/internal/detector"
+	"tech-debt-collector/internal/models"
+	"tech-debt-collector/internal/scanner"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -3,0 +4,2 @@ func main() {
++	// TODO: new
++	x := 1
+@@ -10 +12 @@ func other() {
+-	old()
++	updated()
+@@ -20,3 +21,0 @@
+diff --git a/gone.go b/gone.go
+--- a/gone.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+`
+	cs, err := ParseDiff([]byte(diff))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, cs.Paths())
+	assert.Equal(t, []LineRange{{4, 5}, {12, 12}}, cs.Files["main.go"])
+	assert.True(t, cs.Touches("main.go", 5))
+	assert.False(t, cs.Touches("main.go", 6))
+	assert.False(t, cs.Touches("gone.go", 1))
+
+	_, err = ParseDiff([]byte("+++ b/x.go\n@@ bogus @@\n"))
+	assert.Error(t, err)
+}
+
+// testRepo creates a throwaway git repository and returns its path
+// along with helpers to run git and write files in it
+func testRepo(t *testing.T) (string, func(...string), func(string, string)) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_SYSTEM=/dev/null")
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	git("init", "-q", "-b", "main")
+	git("config", "user.email", "dev@example.com")
+	git("config", "user.name", "dev")
+	return dir, git, write
+}
+
+func TestChangedSinceAndStaged(t *testing.T) {
+	dir, git, write := testRepo(t)
+	write("a.go", "package a\n// TODO: old\n")
+	write("b.go", "package b\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+
+	git("checkout", "-q", "-b", "feature")
+	write("a.go", "package a\n// TODO: old\n// FIXME: new\n")
+	write("pkg/c.go", "package pkg\n// HACK: added\n")
+	git("add", "pkg/c.go")
+
+	ctx := context.Background()
+	cs, err := ChangedSince(ctx, dir, "main")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.go", "pkg/c.go"}, cs.Paths())
+	assert.Equal(t, []LineRange{{3, 3}}, cs.Files["a.go"])
+
+	items := []models.DebtItem{
+		{FilePath: filepath.Join(dir, "a.go"), LineNumber: 2},
+		{FilePath: filepath.Join(dir, "a.go"), LineNumber: 3},
+		{FilePath: filepath.Join(dir, "pkg", "c.go"), LineNumber: 2},
+		{FilePath: filepath.Join(dir, "b.go"), LineNumber: 1},
+	}
+	kept := cs.FilterItems(dir, items)
+	assert.Len(t, kept, 2)
+	assert.Equal(t, 3, kept[0].LineNumber)
+
+	staged, err := StagedChanges(ctx, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pkg/c.go"}, staged.Paths())
+
+	_, err = ChangedSince(ctx, dir, "no-such-ref")
+	assert.Error(t, err)
+}
+
+func TestOpenTree(t *testing.T) {
+	dir, git, write := testRepo(t)
+	write("main.go", "package main\n// TODO: release debt\n")
+	write("pkg/util/util.go", "package util\n// FIXME: old bug\n")
+	write("pkg/README.md", "docs\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "v1")
+	git("tag", "v1.4")
+
+	// Later edits must not leak into the tagged tree
+	write("main.go", "package main\n")
+	git("rm", "-q", "pkg/util/util.go")
+	git("commit", "-q", "-am", "v2")
+
+	ctx := context.Background()
+	tree, err := OpenTree(ctx, dir, "v1.4")
+	assert.NoError(t, err)
+	defer tree.Close()
+
+	assert.NoError(t, fstest.TestFS(tree, "main.go", "pkg/util/util.go", "pkg/README.md"))
+
+	s := scanner.NewScanner("", nil, nil, true)
+	s.FS = tree
+	files, err := s.ScanFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.go", "pkg/util/util.go"}, files)
+
+	items, err := detector.NewDetector().DetectInFS(tree, "pkg/util/util.go", 3)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "FIXME", items[0].Type)
+	assert.Equal(t, "pkg/util/util.go", items[0].FilePath)
+
+	// Trees opened from a subdirectory are scoped to it
+	sub, err := OpenTree(ctx, filepath.Join(dir, "pkg"), "v1.4")
+	assert.NoError(t, err)
+	defer sub.Close()
+	assert.NoError(t, fstest.TestFS(sub, "util/util.go", "README.md"))
+
+	_, err = OpenTree(ctx, dir, "v9.9")
+	assert.Error(t, err)
+}
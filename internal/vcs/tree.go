@@ -0,0 +1,271 @@
+package vcs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// treeEntry is a blob listed by git ls-tree
+type treeEntry struct {
+	object string
+	size   int64
+	mode   fs.FileMode
+}
+
+// TreeFS is a read-only fs.FS over the tree of a git revision. Blobs are
+// read on demand through a single long-running "git cat-file --batch".
+type TreeFS struct {
+	ref   string
+	files map[string]treeEntry
+	dirs  map[string][]fs.DirEntry
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// OpenTree lists the tree of ref below dir. The returned TreeFS must be closed.
+func OpenTree(ctx context.Context, dir, ref string) (*TreeFS, error) {
+	out, err := runGit(ctx, dir, "ls-tree", "-r", "-z", "--long", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TreeFS{
+		ref:   ref,
+		files: make(map[string]treeEntry),
+		dirs:  map[string][]fs.DirEntry{".": nil},
+	}
+	if err := t.index(out); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	t.cmd, t.stdin, t.stdout = cmd, stdin, bufio.NewReader(stdout)
+
+	return t, nil
+}
+
+// index parses "<mode> <type> <object> <size>\t<path>" records
+func (t *TreeFS) index(out []byte) error {
+	for _, record := range bytes.Split(out, []byte{0}) {
+		if len(record) == 0 {
+			continue
+		}
+		meta, name, ok := strings.Cut(string(record), "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			return fmt.Errorf("unexpected ls-tree output: %q", record)
+		}
+
+		// Only regular blobs; submodules and symlinks have no scannable content
+		if fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("unexpected ls-tree size: %q", record)
+		}
+
+		mode := fs.FileMode(0644)
+		if fields[0] == "100755" {
+			mode = 0755
+		}
+		entry := treeEntry{object: fields[2], size: size, mode: mode}
+		t.files[name] = entry
+		t.link(name, treeInfo{name: path.Base(name), size: size, mode: mode})
+	}
+
+	for dir := range t.dirs {
+		entries := t.dirs[dir]
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	}
+	return nil
+}
+
+// link adds name to its parent directory, creating ancestors as needed
+func (t *TreeFS) link(name string, info treeInfo) {
+	parent := path.Dir(name)
+	_, exists := t.dirs[parent]
+	t.dirs[parent] = append(t.dirs[parent], fs.FileInfoToDirEntry(info))
+	if !exists {
+		t.link(parent, treeInfo{name: path.Base(parent), mode: fs.ModeDir | 0755})
+	}
+}
+
+// Ref returns the revision this tree was read from
+func (t *TreeFS) Ref() string {
+	return t.ref
+}
+
+// Open implements fs.FS
+func (t *TreeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if entries, ok := t.dirs[name]; ok {
+		return &treeDir{info: t.dirInfo(name), entries: entries}, nil
+	}
+
+	entry, ok := t.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	data, err := t.readBlob(entry.object)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &treeFile{
+		info:   treeInfo{name: path.Base(name), size: entry.size, mode: entry.mode},
+		Reader: bytes.NewReader(data),
+	}, nil
+}
+
+// ReadDir implements fs.ReadDirFS
+func (t *TreeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, ok := t.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+// Stat implements fs.StatFS
+func (t *TreeFS) Stat(name string) (fs.FileInfo, error) {
+	if _, ok := t.dirs[name]; ok {
+		return t.dirInfo(name), nil
+	}
+	if entry, ok := t.files[name]; ok {
+		return treeInfo{name: path.Base(name), size: entry.size, mode: entry.mode}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// Close stops the background cat-file process
+func (t *TreeFS) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cmd == nil {
+		return nil
+	}
+	t.stdin.Close()
+	err := t.cmd.Wait()
+	t.cmd = nil
+	return err
+}
+
+func (t *TreeFS) dirInfo(name string) treeInfo {
+	return treeInfo{name: path.Base(name), mode: fs.ModeDir | 0755}
+}
+
+// readBlob fetches one object through the batch process
+func (t *TreeFS) readBlob(object string) ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cmd == nil {
+		return nil, fs.ErrClosed
+	}
+	if _, err := fmt.Fprintln(t.stdin, object); err != nil {
+		return nil, err
+	}
+
+	header, err := t.stdout.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("git cat-file: %s", strings.TrimSpace(header))
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file: bad header %q", header)
+	}
+
+	data := make([]byte, size+1) // Content is followed by a newline
+	if _, err := io.ReadFull(t.stdout, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+// treeInfo implements fs.FileInfo for tree entries
+type treeInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (i treeInfo) Name() string       { return i.name }
+func (i treeInfo) Size() int64        { return i.size }
+func (i treeInfo) Mode() fs.FileMode  { return i.mode }
+func (i treeInfo) ModTime() time.Time { return time.Time{} }
+func (i treeInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i treeInfo) Sys() any           { return nil }
+
+// treeFile is an open blob
+type treeFile struct {
+	info treeInfo
+	*bytes.Reader
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *treeFile) Close() error               { return nil }
+
+// treeDir is an open directory
+type treeDir struct {
+	info    treeInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *treeDir) Close() error               { return nil }
+
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile
+func (d *treeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return append([]fs.DirEntry(nil), rest...), nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return append([]fs.DirEntry(nil), rest[:n]...), nil
+}